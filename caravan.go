@@ -23,12 +23,28 @@ func NewStream[Msg any](
 	return BasicStream(source, node.Subprocess(rest...))
 }
 
-// BasicStream instantiates a new Stream that must be started with the Start
-// method.
+// NewStreamWith instantiates a new Stream with the provided Options applied,
+// given a set of Processors
+func NewStreamWith[Msg any](
+	opts []stream.Option, source stream.Processor[stream.Source, Msg],
+	rest ...stream.Processor[Msg, Msg],
+) stream.Stream {
+	return BasicStream(source, node.Subprocess(rest...), opts...)
+}
+
+// BasicStream instantiates a new Stream with the provided Options applied. It
+// must be started with the Start method.
 func BasicStream[In, Out any](
 	source stream.Processor[stream.Source, In], rest stream.Processor[In, Out],
+	opts ...stream.Option,
 ) stream.Stream {
-	return streamImpl.Make(source, rest)
+	return streamImpl.Make(source, rest, opts...)
+}
+
+// NewCheckpointStore instantiates a new CheckpointStore that keeps a Stream's
+// most recent Snapshot in memory
+func NewCheckpointStore() stream.CheckpointStore {
+	return streamImpl.MakeMemoryStore()
 }
 
 // NewTable instantiates a new Table given a set of column names
@@ -0,0 +1,163 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+type (
+	// Stopper is a value, such as a running Stream, that must be explicitly
+	// stopped
+	Stopper interface {
+		// Stop instructs the Stopper to stop processing
+		Stop() error
+
+		// IsRunning returns whether the Stopper is still processing
+		IsRunning() bool
+	}
+
+	// waiter is implemented by Stoppers that can report when they have
+	// completely finished, such as a running Stream
+	waiter interface {
+		Done() <-chan struct{}
+	}
+
+	// Group manages the lifecycle of a set of Closers and Stoppers, closing
+	// them in the reverse order in which they were added
+	Group struct {
+		members []*member
+		timeout time.Duration
+		closed  bool
+		mu      sync.Mutex
+	}
+
+	member struct {
+		name string
+		stop func() error
+	}
+)
+
+var (
+	ErrStopTimeout  = errors.New("timed out waiting to stop")
+	ErrStopFailed   = errors.New("failed to stop")
+	ErrStillRunning = errors.New("still running after stop")
+)
+
+// MakeGroup instantiates a new Group
+func MakeGroup() *Group {
+	return &Group{}
+}
+
+// Add registers a named Closer with the Group. If the Group has already been
+// closed, the Closer is closed immediately, waiting no longer than the
+// Duration that was provided to Close
+func (g *Group) Add(name string, c Closer) {
+	g.add(&member{
+		name: name,
+		stop: func() error {
+			c.Close()
+			<-c.IsClosed()
+			return nil
+		},
+	})
+}
+
+// AddStopper registers a named Stopper with the Group. If the Stopper is
+// capable of reporting when it has finished, such as a running Stream, the
+// Group will wait for it to do so. If the Group has already been closed, the
+// Stopper is stopped immediately, waiting no longer than the Duration that was
+// provided to Close
+func (g *Group) AddStopper(name string, s Stopper) {
+	g.add(&member{
+		name: name,
+		stop: func() error {
+			if !s.IsRunning() {
+				return nil
+			}
+			if err := s.Stop(); err != nil {
+				return err
+			}
+			if w, ok := s.(waiter); ok {
+				<-w.Done()
+			}
+			if s.IsRunning() {
+				return ErrStillRunning
+			}
+			return nil
+		},
+	})
+}
+
+func (g *Group) add(m *member) {
+	g.mu.Lock()
+	if g.closed {
+		d := g.timeout
+		g.mu.Unlock()
+		_ = closeMembers([]*member{m}, d)
+		return
+	}
+	defer g.mu.Unlock()
+	g.members = append(g.members, m)
+}
+
+// Close closes the Group's members in the reverse order in which they were
+// added, waiting up to the specified Duration for all of them to stop. A
+// member is only stopped once those added after it have stopped, unless the
+// Duration elapses first. The members that remain at that point are all
+// stopped at once, without waiting for them. The returned error reports each
+// member that failed to stop, or didn't stop in time
+func (g *Group) Close(d time.Duration) error {
+	g.mu.Lock()
+	members := slices.Clone(g.members)
+	g.members = nil
+	g.timeout = d
+	g.closed = true
+	g.mu.Unlock()
+
+	slices.Reverse(members)
+	return closeMembers(members, d)
+}
+
+func closeMembers(members []*member, d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	var errs []error
+	for _, m := range members {
+		if ctx.Err() != nil {
+			// stopped in the background, so nothing registered is leaked
+			go func() { _ = m.stop() }()
+			errs = append(errs, m.timedOut())
+			continue
+		}
+		if err := m.close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *member) close(ctx context.Context) error {
+	res := make(chan error, 1)
+	go func() {
+		res <- m.stop()
+	}()
+
+	select {
+	case err := <-res:
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrStopFailed, m.name, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return m.timedOut()
+	}
+}
+
+func (m *member) timedOut() error {
+	return fmt.Errorf("%w: %s", ErrStopTimeout, m.name)
+}
@@ -0,0 +1,178 @@
+package closer_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/closer"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+// mockStopper is a test implementation of the Stopper interface
+type mockStopper struct {
+	err     error
+	block   chan struct{}
+	running bool
+	mu      sync.Mutex
+}
+
+func (s *mockStopper) Stop() error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.running = false
+	return nil
+}
+
+func (s *mockStopper) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// orderedCloser records the order in which it was closed
+type orderedCloser struct {
+	*mockCloser
+	name  string
+	order *[]string
+}
+
+func (c *orderedCloser) Close() {
+	*c.order = append(*c.order, c.name)
+	c.mockCloser.Close()
+}
+
+func TestGroupCloseOrder(t *testing.T) {
+	as := assert.New(t)
+
+	var order []string
+	g := closer.MakeGroup()
+	for _, name := range []string{"first", "second", "third"} {
+		g.Add(name, &orderedCloser{
+			mockCloser: newMockCloser(),
+			name:       name,
+			order:      &order,
+		})
+	}
+
+	as.Nil(g.Close(time.Second))
+	as.Equal([]string{"third", "second", "first"}, order)
+}
+
+func TestGroupTopicMembers(t *testing.T) {
+	as := assert.New(t)
+
+	top := caravan.NewTopic[string]()
+	p := top.NewProducer()
+	c := top.NewConsumer()
+	s := caravan.NewStream(
+		node.Generate(func() (string, bool) { return "hello", true }),
+	)
+
+	g := closer.MakeGroup()
+	g.Add("producer", p)
+	g.Add("consumer", c)
+	g.AddStopper("stream", s.Start())
+
+	as.Nil(g.Close(time.Second))
+	as.True(closer.IsClosed(p))
+	as.True(closer.IsClosed(c))
+}
+
+func TestGroupFailures(t *testing.T) {
+	as := assert.New(t)
+
+	errBoom := errors.New("boom")
+	block := make(chan struct{})
+	defer close(block)
+
+	g := closer.MakeGroup()
+	g.AddStopper("stuck", &mockStopper{running: true, block: block})
+	ok := newMockCloser()
+	g.Add("ok", ok)
+	g.AddStopper("failing", &mockStopper{running: true, err: errBoom})
+	g.AddStopper("stopped", &mockStopper{})
+
+	err := g.Close(20 * time.Millisecond)
+	as.ErrorIs(err, closer.ErrStopTimeout)
+	as.ErrorIs(err, closer.ErrStopFailed)
+	as.ErrorIs(err, errBoom)
+	as.Contains(err.Error(), "stuck")
+	as.Contains(err.Error(), "failing")
+	as.NotContains(err.Error(), "ok")
+	as.NotContains(err.Error(), "stopped")
+	as.True(closer.IsClosed(ok))
+}
+
+func TestGroupAddAfterClose(t *testing.T) {
+	as := assert.New(t)
+
+	g := closer.MakeGroup()
+	as.Nil(g.Close(time.Second))
+
+	c := newMockCloser()
+	g.Add("late", c)
+	as.True(closer.IsClosed(c))
+
+	s := &mockStopper{running: true}
+	g.AddStopper("late", s)
+	as.False(s.IsRunning())
+}
+
+func TestGroupCloseDeadline(t *testing.T) {
+	as := assert.New(t)
+
+	block := make(chan struct{})
+	defer close(block)
+
+	g := closer.MakeGroup()
+	first := &mockStopper{running: true}
+	g.AddStopper("first", first)
+	g.AddStopper("second", &mockStopper{running: true, block: block})
+	g.AddStopper("third", &mockStopper{running: true, block: block})
+
+	start := time.Now()
+	err := g.Close(50 * time.Millisecond)
+	as.Less(time.Since(start), 100*time.Millisecond)
+	as.ErrorIs(err, closer.ErrStopTimeout)
+	as.Contains(err.Error(), "third")
+	as.Contains(err.Error(), "second")
+	as.Contains(err.Error(), "first")
+
+	// members that remain after the deadline are still stopped
+	as.Eventually(func() bool {
+		return !first.IsRunning()
+	}, time.Second, time.Millisecond)
+}
+
+func TestGroupAddAfterCloseTimeout(t *testing.T) {
+	as := assert.New(t)
+
+	block := make(chan struct{})
+	defer close(block)
+
+	g := closer.MakeGroup()
+	as.Nil(g.Close(20 * time.Millisecond))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.AddStopper("late", &mockStopper{running: true, block: block})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		as.Fail("AddStopper should not block after Close")
+	}
+}
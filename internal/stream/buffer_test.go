@@ -0,0 +1,74 @@
+package stream_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+func TestStreamBuffer(t *testing.T) {
+	as := assert.New(t)
+
+	gate := make(chan struct{})
+	r := caravan.NewStream(
+		node.Generate(func() (int, bool) { return 1, true }),
+		node.Map(func(i int) int {
+			<-gate
+			return i
+		}),
+	).With(stream.WithBuffer(4)).Start()
+	defer func() { _ = r.Stop() }()
+	defer close(gate)
+
+	as.Eventually(func() bool {
+		n, ok := r.Metrics().Node("/1")
+		return ok && n.Queued == 4 && n.Capacity == 4
+	}, time.Second, time.Millisecond)
+}
+
+func TestCheckpointAsync(t *testing.T) {
+	as := assert.New(t)
+
+	in := caravan.NewTopic[int]()
+	p := in.NewProducer()
+	defer p.Close()
+	out := make(chan int)
+	store := caravan.NewCheckpointStore()
+	cp := stream.Checkpointing{
+		Store:    store,
+		Interval: 5 * time.Millisecond,
+	}
+
+	makeStream := func() stream.Stream {
+		return caravan.NewStream(
+			node.TopicConsumer(in),
+			node.Async[int](8),
+			node.ScanFrom(func(acc, i int) int { return acc + i }, 0),
+			node.SidechainTo(out),
+		).With(stream.WithCheckpointing(cp), stream.WithBuffer(2))
+	}
+
+	s := makeStream().Start()
+	for i := 1; i <= 3; i++ {
+		p.Send() <- i
+	}
+	as.Equal(1, <-out)
+	as.Equal(3, <-out)
+	as.Equal(6, <-out)
+
+	as.Eventually(
+		checkpointed(store, "value", 6), time.Second, time.Millisecond,
+	)
+	as.Nil(s.Stop())
+	as.Nil(s.Wait())
+
+	s = makeStream().Start()
+	defer func() { _ = s.Stop() }()
+	p.Send() <- 4
+	as.Equal(10, <-out)
+}
@@ -0,0 +1,167 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+)
+
+// checkpointer periodically sends Barriers through the Processors of a single
+// attempt at running a Stream, saving the state they capture and then
+// committing the side effects held back by the Processors
+type checkpointer struct {
+	stream.Checkpointing
+	barriers chan *context.Barrier
+	returned chan *context.Barrier
+	final    *context.Barrier
+	restored *stream.Snapshot
+	pending  []*context.Barrier
+	stopped  chan struct{}
+	id       uint64
+}
+
+var (
+	ErrCheckpointLoad = errors.New("could not load checkpoint")
+	ErrCheckpointSave = errors.New("could not save checkpoint")
+)
+
+const defaultCheckpointInterval = time.Second
+
+func makeCheckpointer(cp stream.Checkpointing) (*checkpointer, error) {
+	if cp.Store == nil {
+		return nil, nil
+	}
+	if cp.Interval <= 0 {
+		cp.Interval = defaultCheckpointInterval
+	}
+	res := &checkpointer{
+		Checkpointing: cp,
+		barriers:      make(chan *context.Barrier),
+		returned:      make(chan *context.Barrier),
+		final:         context.MakeBarrier(0),
+		stopped:       make(chan struct{}),
+	}
+	snap, ok, err := cp.Store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCheckpointLoad, err)
+	}
+	if ok {
+		res.restored = snap
+		res.id = snap.ID
+	}
+	return res, nil
+}
+
+// attach returns a copy of the Context that carries the checkpointer's
+// Barriers and the state of the Snapshot it restored, if any
+func (cp *checkpointer) attach(
+	c *context.Context[stream.Source, stream.Sink],
+) *context.Context[stream.Source, stream.Sink] {
+	if cp == nil {
+		return c
+	}
+	c = context.WithBarriers(c, cp.barriers, cp.returned)
+	c = context.WithFinal(c, cp.final)
+	if cp.Codec != nil {
+		c = context.WithCodec(c, cp.Codec)
+	}
+	if cp.restored != nil {
+		c = context.WithRestored(c, cp.restored.State)
+	}
+	return c
+}
+
+// start begins sending Barriers through the Processors of the Context at the
+// checkpointer's Interval, until they're done or have all returned. If they
+// return without being stopped, a final Snapshot is saved of the state
+// they're left in
+func (cp *checkpointer) start(
+	c *context.Context[stream.Source, stream.Sink], finished <-chan context.Done,
+) {
+	if cp == nil {
+		return
+	}
+	go func() {
+		defer close(cp.stopped)
+		ticker := time.NewTicker(cp.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.Done:
+				return
+			case <-finished:
+				cp.finish(c)
+				return
+			case <-ticker.C:
+			}
+
+			cp.id++
+			b := context.MakeBarrier(cp.id)
+			select {
+			case <-c.Done:
+				return
+			case <-finished:
+				cp.finish(c)
+				return
+			case cp.barriers <- b:
+			}
+			cp.pending = append(cp.pending, b)
+			select {
+			case <-c.Done:
+				return
+			case <-finished:
+				cp.finish(c)
+				return
+			case <-cp.returned:
+			}
+			cp.save(c, b)
+		}
+	}()
+}
+
+// wait blocks until the checkpointer has saved its last Snapshot
+func (cp *checkpointer) wait() {
+	if cp != nil {
+		<-cp.stopped
+	}
+}
+
+// finish saves the state of the Processors once they've all returned. If
+// they were stopped instead, their state is incomplete, and it's discarded
+// along with the side effects they've held back
+func (cp *checkpointer) finish(c *context.Context[stream.Source, stream.Sink]) {
+	if c.IsDone() {
+		return
+	}
+	cp.id++
+	cp.final.ID = cp.id
+	cp.final.Capture()
+	cp.pending = append(cp.pending, cp.final)
+	cp.save(c, cp.final)
+}
+
+// save stores the state captured by the Barrier, and then commits the side
+// effects of every Barrier that's pending. If the state couldn't be captured
+// or the Snapshot can't be saved, they remain pending until one can
+func (cp *checkpointer) save(
+	c *context.Context[stream.Source, stream.Sink], b *context.Barrier,
+) {
+	err := b.Err()
+	if err == nil {
+		err = cp.Store.Save(&stream.Snapshot{
+			ID:    b.ID,
+			State: b.State(),
+		})
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("%w: %w", ErrCheckpointSave, err))
+		return
+	}
+	for _, p := range cp.pending {
+		p.Commit()
+	}
+	cp.pending = nil
+}
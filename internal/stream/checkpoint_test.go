@@ -0,0 +1,401 @@
+package stream_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+	"github.com/kode4food/caravan/topic"
+)
+
+func makeSummingStream(
+	in topic.Topic[int], out chan<- int, mapper func(int) int,
+) stream.Stream {
+	return caravan.NewStream(
+		node.TopicConsumer(in),
+		node.Map(mapper),
+		node.ScanFrom(func(acc, i int) int { return acc + i }, 0),
+		node.SidechainTo(out),
+	)
+}
+
+func checkpointed(store stream.CheckpointStore, name string, v any) func() bool {
+	return func() bool {
+		snap, ok, _ := store.Load()
+		if !ok {
+			return false
+		}
+		for k, s := range snap.State {
+			if strings.HasSuffix(k, "/"+name) && s == v {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func identity(i int) int { return i }
+
+func TestCheckpointRestore(t *testing.T) {
+	as := assert.New(t)
+
+	in := caravan.NewTopic[int]()
+	p := in.NewProducer()
+	defer p.Close()
+	out := make(chan int)
+	store := caravan.NewCheckpointStore()
+	cp := stream.Checkpointing{
+		Store:    store,
+		Interval: 5 * time.Millisecond,
+	}
+
+	s := makeSummingStream(in, out, identity).
+		With(stream.WithCheckpointing(cp)).
+		Start()
+	for i := 1; i <= 3; i++ {
+		p.Send() <- i
+	}
+	as.Equal(1, <-out)
+	as.Equal(3, <-out)
+	as.Equal(6, <-out)
+
+	as.Eventually(
+		checkpointed(store, "offset", uint64(3)), time.Second, time.Millisecond,
+	)
+	as.Nil(s.Stop())
+	as.Nil(s.Wait())
+
+	s = makeSummingStream(in, out, identity).
+		With(stream.WithCheckpointing(cp)).
+		Start()
+	defer func() { _ = s.Stop() }()
+	p.Send() <- 4
+	as.Equal(10, <-out)
+}
+
+func TestCheckpointRestartStream(t *testing.T) {
+	as := assert.New(t)
+
+	in := caravan.NewTopic[int]()
+	p := in.NewProducer()
+	defer p.Close()
+	out := make(chan int)
+	store := caravan.NewCheckpointStore()
+
+	panicked := false
+	s := makeSummingStream(in, out, func(i int) int {
+		if i == 4 && !panicked {
+			panicked = true
+			panic("boom")
+		}
+		return i
+	}).With(stream.WithCheckpointing(stream.Checkpointing{
+		Store:    store,
+		Interval: 5 * time.Millisecond,
+	}), stream.WithSupervision(stream.Supervision{
+		Policy:  stream.RestartStream,
+		Backoff: time.Millisecond,
+	})).StartWith(func(context.Advice, func()) {})
+	defer func() { _ = s.Stop() }()
+
+	for i := 1; i <= 3; i++ {
+		p.Send() <- i
+		<-out
+	}
+	as.Eventually(
+		checkpointed(store, "value", 6), time.Second, time.Millisecond,
+	)
+
+	p.Send() <- 4
+	as.Equal(10, <-out)
+	as.True(panicked)
+}
+
+func TestCheckpointMerge(t *testing.T) {
+	as := assert.New(t)
+
+	left := caravan.NewTopic[int]()
+	right := caravan.NewTopic[int]()
+	lp := left.NewProducer()
+	defer lp.Close()
+	rp := right.NewProducer()
+	defer rp.Close()
+	out := make(chan int)
+	store := caravan.NewCheckpointStore()
+
+	s := caravan.NewStream(
+		node.Merge(node.TopicConsumer(left), node.TopicConsumer(right)),
+		node.ScanFrom(func(acc, i int) int { return acc + i }, 0),
+		node.SidechainTo(out),
+	).With(stream.WithCheckpointing(stream.Checkpointing{
+		Store:    store,
+		Interval: 5 * time.Millisecond,
+	})).Start()
+	defer func() { _ = s.Stop() }()
+
+	lp.Send() <- 1
+	<-out
+	rp.Send() <- 2
+	as.Equal(3, <-out)
+	as.Eventually(
+		checkpointed(store, "value", 3), time.Second, time.Millisecond,
+	)
+
+	snap, _, _ := store.Load()
+	var offsets int
+	for k, v := range snap.State {
+		if strings.HasSuffix(k, "/offset") {
+			as.Equal(uint64(1), v)
+			offsets++
+		}
+	}
+	as.Equal(2, offsets)
+}
+
+func TestCheckpointZip(t *testing.T) {
+	as := assert.New(t)
+
+	count := 0
+	out := make(chan node.Pair[int, int])
+	store := caravan.NewCheckpointStore()
+	s := caravan.NewStream(
+		node.Zip(
+			node.Generate(func() (int, bool) {
+				count++
+				return count, true
+			}),
+			node.TopicConsumer(caravan.NewTopic[int]()),
+		),
+		node.SidechainTo(out),
+	).With(stream.WithCheckpointing(stream.Checkpointing{
+		Store:    store,
+		Interval: time.Millisecond,
+	})).Start()
+	defer func() { _ = s.Stop() }()
+
+	as.Eventually(func() bool {
+		snap, ok, _ := store.Load()
+		return ok && snap.ID > 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestCheckpointParallelMap(t *testing.T) {
+	as := assert.New(t)
+
+	in := caravan.NewTopic[int]()
+	p := in.NewProducer()
+	defer p.Close()
+	out := make(chan int)
+	store := caravan.NewCheckpointStore()
+	cp := stream.Checkpointing{
+		Store:    store,
+		Interval: 5 * time.Millisecond,
+	}
+
+	makeStream := func() stream.Stream {
+		return caravan.NewStream(
+			node.TopicConsumer(in),
+			node.ParallelMap(4, node.Ordered, func(i int) (int, error) {
+				return i, nil
+			}),
+			node.ScanFrom(func(acc, i int) int { return acc + i }, 0),
+			node.SidechainTo(out),
+		).With(stream.WithCheckpointing(cp))
+	}
+
+	s := makeStream().Start()
+	for i := 1; i <= 3; i++ {
+		p.Send() <- i
+	}
+	as.Equal(1, <-out)
+	as.Equal(3, <-out)
+	as.Equal(6, <-out)
+
+	as.Eventually(
+		checkpointed(store, "value", 6), time.Second, time.Millisecond,
+	)
+	as.Nil(s.Stop())
+	as.Nil(s.Wait())
+
+	s = makeStream().Start()
+	defer func() { _ = s.Stop() }()
+	p.Send() <- 4
+	as.Equal(10, <-out)
+}
+
+func receiveAll(c topic.Consumer[int], n int) []int {
+	res := make([]int, n)
+	for i := range res {
+		res[i] = <-c.Receive()
+	}
+	return res
+}
+
+func TestCheckpointExactlyOnce(t *testing.T) {
+	as := assert.New(t)
+
+	in := caravan.NewTopic[int]()
+	p := in.NewProducer()
+	defer p.Close()
+	out := caravan.NewTopic[int]()
+	c := out.NewConsumer()
+	defer c.Close()
+
+	panicked := false
+	s := caravan.NewStream(
+		node.TopicConsumer(in),
+		node.Map(func(i int) int {
+			if i == 4 && !panicked {
+				panicked = true
+				panic("boom")
+			}
+			return i
+		}),
+		node.ScanFrom(func(acc, i int) int { return acc + i }, 0),
+		node.TopicProducer(out),
+	).With(stream.WithCheckpointing(stream.Checkpointing{
+		Store:    caravan.NewCheckpointStore(),
+		Interval: 5 * time.Millisecond,
+	}), stream.WithSupervision(stream.Supervision{
+		Policy:  stream.RestartStream,
+		Backoff: time.Millisecond,
+	})).StartWith(func(context.Advice, func()) {})
+	defer func() { _ = s.Stop() }()
+
+	for i := 1; i <= 5; i++ {
+		p.Send() <- i
+	}
+	as.Equal([]int{1, 3, 6, 10, 15}, receiveAll(c, 5))
+	as.True(panicked)
+
+	select {
+	case i := <-c.Receive():
+		as.Fail("unexpected message", i)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCheckpointFinal(t *testing.T) {
+	as := assert.New(t)
+
+	out := caravan.NewTopic[int]()
+	c := out.NewConsumer()
+	defer c.Close()
+
+	i, limit := 0, 3
+	s := caravan.NewStream(
+		node.Generate(func() (int, bool) {
+			i++
+			return i, i <= limit
+		}),
+		node.ScanFrom(func(acc, i int) int { return acc + i }, 0),
+		node.TopicProducer(out),
+	).With(stream.WithCheckpointing(stream.Checkpointing{
+		Store:    caravan.NewCheckpointStore(),
+		Interval: time.Hour,
+	}))
+
+	// nothing is checkpointed until the Stream completes
+	as.Nil(s.Start().Wait())
+	as.Equal([]int{1, 3, 6}, receiveAll(c, 3))
+
+	i, limit = 3, 4
+	as.Nil(s.Start().Wait())
+	as.Equal([]int{10}, receiveAll(c, 1))
+}
+
+// encodingStore keeps its Snapshot as gob encoded bytes, as a store that
+// keeps it outside the process would
+type encodingStore struct {
+	data []byte
+	mu   sync.Mutex
+}
+
+func (s *encodingStore) Save(snap *stream.Snapshot) error {
+	data, err := stream.GobCodec{}.Encode(snap)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = data
+	return nil
+}
+
+func (s *encodingStore) Load() (*stream.Snapshot, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		return nil, false, nil
+	}
+	var res stream.Snapshot
+	if err := (stream.GobCodec{}).Decode(s.data, &res); err != nil {
+		return nil, false, err
+	}
+	return &res, true, nil
+}
+
+func TestCheckpointCodec(t *testing.T) {
+	as := assert.New(t)
+
+	in := caravan.NewTopic[string]()
+	p := in.NewProducer()
+	defer p.Close()
+	out := make(chan node.KeyedValue[string, int])
+	store := &encodingStore{}
+	cp := stream.Checkpointing{
+		Store:    store,
+		Interval: 5 * time.Millisecond,
+		Codec:    stream.GobCodec{},
+	}
+
+	makeStream := func() stream.Stream {
+		return caravan.BasicStream(
+			node.TopicConsumer(in),
+			node.Bind(
+				node.Distinct(func(l, r string) bool { return l == r }),
+				node.Bind(
+					node.GroupBy(func(s string) string { return s }),
+					node.Bind(
+						node.CountByKey[string, string](),
+						node.SidechainTo(out),
+					),
+				),
+			),
+		).With(stream.WithCheckpointing(cp))
+	}
+
+	s := makeStream().Start()
+	for _, k := range []string{"a", "b", "a"} {
+		p.Send() <- k
+		<-out
+	}
+	as.Eventually(func() bool {
+		snap, ok, err := store.Load()
+		if !ok || err != nil {
+			return false
+		}
+		for k, v := range snap.State {
+			if strings.HasSuffix(k, "/offset") {
+				var offset struct{ State uint64 }
+				err := stream.GobCodec{}.Decode(v.([]byte), &offset)
+				return err == nil && offset.State == 3
+			}
+		}
+		return false
+	}, time.Second, time.Millisecond)
+	as.Nil(s.Stop())
+	as.Nil(s.Wait())
+
+	s = makeStream().Start()
+	defer func() { _ = s.Stop() }()
+	p.Send() <- "b"
+	as.Equal(node.KeyedValue[string, int]{Key: "b", Value: 2}, <-out)
+}
@@ -0,0 +1,76 @@
+package stream_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	testutil "github.com/kode4food/caravan/internal/testing"
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+func recordAttrs(r slog.Record) map[string]any {
+	res := map[string]any{}
+	r.Attrs(func(a slog.Attr) bool {
+		res[a.Key] = a.Value.Any()
+		return true
+	})
+	return res
+}
+
+func nextRecord(t *testing.T, h *testutil.TestSlogHandler) slog.Record {
+	select {
+	case r := <-h.Logs:
+		return r
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for log record")
+		return slog.Record{}
+	}
+}
+
+func TestStreamLogger(t *testing.T) {
+	as := assert.New(t)
+
+	err := errors.New("recoverable")
+	h := testutil.NewTestSlogHandler()
+	r := caravan.NewStreamWith(
+		[]stream.Option{
+			stream.WithName("orders"),
+			stream.WithLogger(slog.New(h)),
+		},
+		node.Generate(func() (int, bool) { return 1, true }),
+		node.Named("noisy", func(c *context.Context[int, int]) {
+			c.Debugf("checking %d", 1)
+			c.Error(err)
+			c.Fatalf("giving up")
+			<-c.Done
+		}),
+	).Start()
+	as.NotNil(r.Wait())
+
+	rec := nextRecord(t, h)
+	as.Equal(slog.LevelDebug, rec.Level)
+	as.Equal("checking 1", rec.Message)
+	attrs := recordAttrs(rec)
+	as.Equal("orders", attrs["stream"])
+	as.Equal("/1", attrs["node"])
+	as.Equal("noisy", attrs["node_name"])
+	as.Contains(attrs["stack"], "goroutine")
+
+	rec = nextRecord(t, h)
+	as.Equal(slog.LevelWarn, rec.Level)
+	attrs = recordAttrs(rec)
+	as.Equal(err, attrs["error"])
+	as.Equal("orders", attrs["stream"])
+
+	rec = nextRecord(t, h)
+	as.Equal(slog.LevelError, rec.Level)
+	attrs = recordAttrs(rec)
+	as.EqualError(attrs["error"].(error), "giving up")
+}
@@ -0,0 +1,95 @@
+package stream_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+type blockedSink struct {
+	blocked chan time.Duration
+}
+
+func (s *blockedSink) Received(string) {}
+
+func (s *blockedSink) Forwarded(id string, _, blocked time.Duration) {
+	if id != "/0" {
+		return
+	}
+	select {
+	case s.blocked <- blocked:
+	default:
+	}
+}
+
+func (s *blockedSink) Failed(string) {}
+
+func TestStreamMetrics(t *testing.T) {
+	as := assert.New(t)
+
+	i := 0
+	r := caravan.NewStream(
+		node.Generate(func() (int, bool) {
+			i++
+			return i, i <= 3
+		}),
+		node.Named("double", node.Map(func(i int) int { return i * 2 })),
+		node.Filter(func(i int) bool { return i > 2 }),
+	).Start()
+	as.Nil(r.Wait())
+
+	snap := r.Metrics()
+	ids := make([]string, len(snap.Nodes))
+	for i, n := range snap.Nodes {
+		ids[i] = n.ID
+	}
+	as.Equal([]string{"/0", "/1/0", "/1/1"}, ids)
+
+	gen, _ := snap.Node("/0")
+	as.Equal("Generate", gen.Kind)
+	as.Equal(uint64(3), gen.Forwarded)
+
+	double, _ := snap.Node("/1/0")
+	as.Equal("double", double.Name)
+	as.Equal("Map", double.Kind)
+	as.Equal(uint64(3), double.Received)
+	as.Equal(uint64(3), double.Forwarded)
+	as.Equal(uint64(3), double.Latency.Count)
+
+	filter, _ := snap.Node("/1/1")
+	as.Equal(uint64(3), filter.Received)
+	as.Equal(uint64(2), filter.Forwarded)
+}
+
+func TestStreamMetricsSink(t *testing.T) {
+	as := assert.New(t)
+
+	sink := &blockedSink{
+		blocked: make(chan time.Duration, 2),
+	}
+	gate := make(chan struct{})
+	r := caravan.NewStream(
+		node.Generate(func() (int, bool) { return 1, true }),
+		node.Map(func(i int) int {
+			<-gate
+			return i
+		}),
+	).With(stream.WithMetrics(sink)).Start()
+	defer func() { _ = r.Stop() }()
+	defer close(gate)
+
+	// the second message is held up by the first
+	time.Sleep(10 * time.Millisecond)
+	gate <- struct{}{}
+	<-sink.blocked
+	as.GreaterOrEqual(<-sink.blocked, 10*time.Millisecond)
+
+	n, ok := r.Metrics().Node("/0")
+	as.True(ok)
+	as.GreaterOrEqual(n.Blocked, 10*time.Millisecond)
+}
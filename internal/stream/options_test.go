@@ -0,0 +1,49 @@
+package stream_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	testutil "github.com/kode4food/caravan/internal/testing"
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+func TestStreamOptions(t *testing.T) {
+	as := assert.New(t)
+
+	err := errors.New("recoverable")
+	h := testutil.NewTestSlogHandler()
+	handled := make(chan context.Advice, 1)
+	s := caravan.BasicStream(
+		node.Generate(func() (int, bool) { return 1, true }),
+		func(c *context.Context[int, int]) {
+			c.Error(err)
+			<-c.Done
+		},
+		stream.WithName("orders"),
+		stream.WithLogger(slog.New(h)),
+		stream.WithAdviceHandler(func(a context.Advice, next func()) {
+			handled <- a
+			next()
+		}),
+	)
+
+	r := s.Start()
+	a := <-handled
+	as.ErrorIs(a.(*context.Error), err)
+	rec := nextRecord(t, h)
+	as.Equal("orders", recordAttrs(rec)["stream"])
+	as.Nil(r.Stop())
+
+	r = s.With(stream.WithName("renamed")).Start()
+	<-handled
+	rec = nextRecord(t, h)
+	as.Equal("renamed", recordAttrs(rec)["stream"])
+	as.Nil(r.Stop())
+}
@@ -0,0 +1,35 @@
+package stream
+
+import (
+	"sync"
+
+	"github.com/kode4food/caravan/stream"
+)
+
+// MemoryStore is a CheckpointStore that keeps its Snapshot in memory. It
+// allows a Stream's state to survive the Stream being restarted, but not the
+// process itself
+type MemoryStore struct {
+	snapshot *stream.Snapshot
+	mu       sync.Mutex
+}
+
+// MakeMemoryStore instantiates a new MemoryStore
+func MakeMemoryStore() stream.CheckpointStore {
+	return &MemoryStore{}
+}
+
+// Save stores the Snapshot, replacing any previous Snapshot
+func (s *MemoryStore) Save(snap *stream.Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshot = snap
+	return nil
+}
+
+// Load returns the most recently saved Snapshot, if there is one
+func (s *MemoryStore) Load() (*stream.Snapshot, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot, s.snapshot != nil, nil
+}
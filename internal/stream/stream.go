@@ -1,41 +1,63 @@
 package stream
 
 import (
-	"log"
+	gocontext "context"
+	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/kode4food/caravan/stream"
 	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/metrics"
 	"github.com/kode4food/caravan/stream/node"
+	"github.com/kode4food/caravan/stream/topology"
 )
 
 type (
 	// Stream is the internal implementation of a stream.Stream
 	Stream[In, Out any] struct {
 		root stream.Processor[stream.Source, Out]
+		opts stream.Options
 	}
 
 	// Running is the internal implementation of a stream.Running
 	Running[In, Out any] struct {
 		*Stream[In, Out]
-		monitor chan context.Advice
-		done    chan context.Done
-		mu      sync.Mutex
+		monitor  chan context.Advice
+		done     chan context.Done
+		drain    chan context.Done
+		stopped  chan context.Done
+		finished chan struct{}
+		attempt  chan context.Done
+		recorder *metrics.Recorder
+		logger   *slog.Logger
+		restarts int
+		restart  bool
+		err      error
+		mu       sync.Mutex
 	}
 )
 
-// Make builds a Stream. The Stream must be started using the Start method
+// Make builds a Stream with the provided Options applied. The Stream must be
+// started using the Start method
 func Make[In, Out any](
 	source stream.Processor[stream.Source, In], rest stream.Processor[In, Out],
+	opts ...stream.Option,
 ) stream.Stream {
 	return &Stream[In, Out]{
 		root: node.Bind(source, rest),
+		opts: stream.Options{}.Apply(opts...),
 	}
 }
 
-// Start kicks off the background routine for this stream
+// Start kicks off the background routine for this stream. If the stream's
+// Options include an AdviceHandler, it's given first crack at the Advice
 func (s *Stream[_, _]) Start() stream.Running {
 	r := s.start()
+	if h := s.opts.AdviceHandler; h != nil {
+		r.startMonitoringWith(h)
+		return r
+	}
 	r.startMonitoringWith(r.handleAdvice)
 	return r
 }
@@ -46,45 +68,205 @@ func (s *Stream[_, _]) StartWith(h stream.AdviceHandler) stream.Running {
 	return r
 }
 
+// With returns a copy of the Stream with the provided Options applied
+func (s *Stream[In, Out]) With(opts ...stream.Option) stream.Stream {
+	res := *s
+	res.opts = s.opts.Apply(opts...)
+	return &res
+}
+
+// Topology describes the Stream's Processors and the connections between them
+func (s *Stream[In, Out]) Topology() *topology.Topology {
+	b := topology.MakeBuilder()
+	done := make(chan context.Done)
+	close(done)
+	c := context.Make[stream.Source, Out](done, nil, nil, nil)
+	s.root.Start(context.WithTopology(c, b))
+	return b.Build()
+}
+
 func (s *Stream[In, Out]) start() *Running[In, Out] {
 	r := &Running[In, Out]{
-		Stream:  s,
-		monitor: make(chan context.Advice),
-		done:    make(chan context.Done),
+		Stream:   s,
+		monitor:  make(chan context.Advice),
+		done:     make(chan context.Done),
+		drain:    make(chan context.Done),
+		stopped:  make(chan context.Done),
+		finished: make(chan struct{}),
+		recorder: metrics.MakeRecorder(s.opts.Metrics),
+		logger:   s.makeLogger(),
 	}
 	r.startStream()
 	return r
 }
 
-func (r *Running[_, Out]) startStream() {
-	go func() {
-		in := make(chan stream.Source)
-		out := make(chan stream.Sink)
-
-		loop := node.Bind(
-			r.root,
-			node.Sink[Out](),
-		)
-
-		loop.Start(context.Make(r.done, r.monitor, in, out))
+func (s *Stream[_, _]) makeLogger() *slog.Logger {
+	res := s.opts.Logger
+	if res == nil {
+		res = slog.Default()
+	}
+	if s.opts.Name != "" {
+		res = res.With(slog.String("stream", s.opts.Name))
+	}
+	return res
+}
 
-		for {
+func (r *Running[_, _]) startStream() {
+	go func() {
+		defer close(r.stopped)
+		defer r.complete()
+		backoff := r.opts.Supervision.Backoff
+		for r.runAttempt() {
+			timer := time.NewTimer(backoff)
 			select {
 			case <-r.done:
+				timer.Stop()
+				return
+			case <-r.drain:
+				timer.Stop()
 				return
-			case in <- stream.Source{}:
+			case <-timer.C:
 			}
+			backoff *= 2
+			if m := r.opts.Supervision.MaxBackoff; m > 0 && backoff > m {
+				backoff = m
+			}
+		}
+	}()
+}
+
+// runAttempt starts the stream's Processors and feeds its source until they
+// have all returned. Returns whether the stream is to be restarted
+func (r *Running[_, Out]) runAttempt() bool {
+	attempt := r.beginAttempt()
+	in := make(chan stream.Source)
+	out := make(chan Out)
+	finished := make(chan context.Done)
+
+	c := context.WithDrain(
+		context.Make(attempt, r.monitor, in, make(chan stream.Sink)), r.drain,
+	)
+	c = context.WithSupervisor(c, r.supervise, r.abandon)
+	c = context.WithMetrics(c, r.recorder)
+	c = context.WithLogger(c, r.logger)
+	c = context.WithBuffer(c, r.opts.Buffer)
+	if t := r.opts.Tracer; t != nil {
+		c = context.WithTracer(c, t)
+	}
+	cp, err := makeCheckpointer(r.opts.Checkpointing)
+	if err != nil {
+		r.fail(err)
+		_ = r.Stop()
+		return false
+	}
+	c = cp.attach(c)
+
+	// the root's output is discarded here rather than by a Sink Processor,
+	// so that its Processors are identified as they are in the Topology
+	go func() {
+		for range out {
 		}
 	}()
+	r.root.Start(context.WithCompletion(context.WithOut(c, out), func() {
+		close(out)
+		close(finished)
+	}))
+	cp.start(c, finished)
+
+	r.feed(in, attempt, finished)
+	close(in)
+	<-finished
+	cp.wait()
+	return r.endAttempt()
+}
+
+func (r *Running[_, _]) feed(
+	in chan<- stream.Source, attempt, finished <-chan context.Done,
+) {
+	for {
+		select {
+		case <-attempt:
+			return
+		case <-r.drain:
+			return
+		case <-finished:
+			return
+		case in <- stream.Source{}:
+		}
+	}
+}
+
+func (r *Running[_, _]) beginAttempt() <-chan context.Done {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempt = make(chan context.Done)
+	if !r.isRunning() {
+		close(r.attempt)
+	}
+	return r.attempt
+}
+
+func (r *Running[_, _]) endAttempt() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := r.restart && r.isRunning() && !r.isDraining()
+	r.restart = false
+	return res
+}
+
+// supervise is called from a panicking Processor's go routine, and decides
+// how it's to be handled based on the stream's Supervision
+func (r *Running[_, _]) supervise(error) context.Decision {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.opts.Supervision
+	if !r.isRunning() || s.MaxRestarts > 0 && r.restarts >= s.MaxRestarts {
+		return context.Escalate
+	}
+	switch s.Policy {
+	case stream.RestartNode:
+		r.restarts++
+		return context.Restart
+	case stream.RestartStream:
+		r.restarts++
+		r.restart = true
+		return context.Abandon
+	default:
+		return context.Escalate
+	}
+}
+
+// abandon is called once an abandoned panic has been reported, and stops the
+// current attempt so that the stream can be restarted
+func (r *Running[_, _]) abandon() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.abort()
+}
+
+// complete marks the stream as stopped once its Processors have returned for
+// the last time, whether or not it was explicitly stopped
+func (r *Running[_, _]) complete() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.isRunning() {
+		r.stop()
+	}
 }
 
 func (r *Running[_, _]) startMonitoringWith(handle stream.AdviceHandler) {
 	go func() {
+		defer close(r.finished)
 		for {
 			select {
-			case <-r.done:
+			case <-r.stopped:
 				return
 			case a := <-r.monitor:
+				// recorded before a custom handler has a chance to ignore it
+				if f, ok := a.(*context.Fatal); ok {
+					r.fail(f)
+				}
 				handle(a, func() {
 					r.handleAdvice(a, nil)
 				})
@@ -95,16 +277,40 @@ func (r *Running[_, _]) startMonitoringWith(handle stream.AdviceHandler) {
 
 func (r *Running[_, _]) handleAdvice(a context.Advice, _ func()) {
 	switch e := a.(type) {
+	case *context.Debug:
+		r.log(slog.LevelDebug, e.Message, e.Origin,
+			slog.String("stack", string(e.Stack)),
+		)
 	case *context.Error:
-		log.Print(e.Error())
+		r.log(slog.LevelWarn, "processor error", e.Origin,
+			slog.Any("error", e.Unwrap()),
+		)
 	case *context.Fatal:
-		log.Print(e.Error())
+		r.log(slog.LevelError, "processor fatal error", e.Origin,
+			slog.Any("error", e.Unwrap()),
+		)
 		_ = r.Stop()
 	case context.Stop:
+		r.log(slog.LevelInfo, "stream stop advised", context.Origin{})
 		_ = r.Stop()
 	}
 }
 
+func (r *Running[_, _]) log(
+	level slog.Level, msg string, o context.Origin, attrs ...slog.Attr,
+) {
+	if o.Node != "" {
+		attrs = append(o.LogAttrs(), attrs...)
+	}
+	r.logger.LogAttrs(gocontext.Background(), level, msg, attrs...)
+}
+
+// Metrics returns a Snapshot of the metrics recorded by the stream's
+// Processors, accumulated across restarts
+func (r *Running[_, _]) Metrics() *metrics.Snapshot {
+	return r.recorder.Snapshot()
+}
+
 // IsRunning returns whether the stream is actively running
 func (r *Running[_, _]) IsRunning() bool {
 	r.mu.Lock()
@@ -135,4 +341,78 @@ func (r *Running[_, _]) Stop() error {
 
 func (r *Running[_, _]) stop() {
 	close(r.done)
+	r.abort()
+}
+
+// abort closes the Done channel of the current attempt's Processors
+func (r *Running[_, _]) abort() {
+	if r.attempt == nil {
+		return
+	}
+	select {
+	case <-r.attempt:
+	default:
+		close(r.attempt)
+	}
+}
+
+func (r *Running[_, _]) isDraining() bool {
+	select {
+	case <-r.drain:
+		return true
+	default:
+		return false
+	}
+}
+
+// fail records the error that terminated the stream. Only the first error
+// recorded is retained
+func (r *Running[_, _]) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Done returns a channel that is closed once all the stream's Processors have
+// returned and their Advice has been handled, whether because the stream
+// completed on its own or because it was stopped
+func (r *Running[_, _]) Done() <-chan struct{} {
+	return r.finished
+}
+
+// Wait blocks until all the stream's Processors have returned, and then
+// returns the error that terminated the stream, if any
+func (r *Running[_, _]) Wait() error {
+	<-r.finished
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Drain stops the stream's sources from pulling new messages, allowing the
+// messages already in flight to reach the sink and stateful Processors to
+// flush what they're holding. Once all Processors have returned, the stream is
+// stopped and the error that terminated it, if any, is returned. If the
+// provided Context is done first, the stream is stopped immediately and the
+// Context's error is returned
+func (r *Running[_, _]) Drain(ctx gocontext.Context) error {
+	r.mu.Lock()
+	if !r.isRunning() {
+		r.mu.Unlock()
+		return stream.ErrAlreadyStopped
+	}
+	if !r.isDraining() {
+		close(r.drain)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.finished:
+		return r.Wait()
+	case <-ctx.Done():
+		_ = r.Stop()
+		return ctx.Err()
+	}
 }
@@ -1,6 +1,8 @@
 package stream_test
 
 import (
+	gocontext "context"
+	"errors"
 	"testing"
 	"time"
 
@@ -125,3 +127,287 @@ func TestStreamFatalError(t *testing.T) {
 	}()
 	<-done
 }
+
+func TestStreamDrain(t *testing.T) {
+	as := assert.New(t)
+
+	in := caravan.NewTopic[int]()
+	out := caravan.NewTopic[[]int]()
+	seen := make(chan int)
+
+	s := caravan.NewStream(
+		node.Bind(
+			node.Bind(node.TopicConsumer(in), node.SidechainTo(seen)),
+			node.Buffer[int](10, time.Minute),
+		),
+		node.TopicProducer(out),
+	).Start()
+
+	p := in.NewProducer()
+	defer p.Close()
+	for i := range 3 {
+		p.Send() <- i
+		as.Equal(i, <-seen)
+	}
+
+	as.Nil(s.Drain(gocontext.Background()))
+	as.False(s.IsRunning())
+
+	c := out.NewConsumer()
+	defer c.Close()
+	as.Equal([]int{0, 1, 2}, <-c.Receive())
+}
+
+func TestStreamDrainWindow(t *testing.T) {
+	as := assert.New(t)
+
+	count := 0
+	seen := make(chan int)
+	out := make(chan []int, 1)
+	s := caravan.NewStream(
+		node.Bind(
+			node.Bind(
+				node.Generate(func() (int, bool) {
+					count++
+					return count, true
+				}),
+				node.SidechainTo(seen),
+			),
+			node.Window[int](time.Minute),
+		),
+		node.SidechainTo(out),
+	).Start()
+
+	var expected []int
+	for range 5 {
+		expected = append(expected, <-seen)
+	}
+	go func() {
+		for range seen {
+		}
+	}()
+
+	as.Nil(s.Drain(gocontext.Background()))
+	as.Equal(expected, (<-out)[:5])
+}
+
+func TestStreamDrainTimeout(t *testing.T) {
+	as := assert.New(t)
+
+	s := caravan.NewStream(
+		node.Generate(func() (any, bool) { return "hello", true }),
+		func(c *context.Context[any, any]) {
+			<-c.Done
+		},
+	).Start()
+
+	ctx, cancel := gocontext.WithTimeout(
+		gocontext.Background(), 20*time.Millisecond,
+	)
+	defer cancel()
+	as.ErrorIs(s.Drain(ctx), gocontext.DeadlineExceeded)
+	as.False(s.IsRunning())
+}
+
+func TestStreamDrainStopped(t *testing.T) {
+	as := assert.New(t)
+
+	s := makeGeneratingStream("hello").Start()
+	as.Nil(s.Stop())
+	as.ErrorIs(
+		s.Drain(gocontext.Background()), stream.ErrAlreadyStopped,
+	)
+}
+
+func TestStreamWaitCompleted(t *testing.T) {
+	as := assert.New(t)
+
+	count := 0
+	var res []int
+	s := caravan.NewStream(
+		node.Generate(func() (int, bool) {
+			count++
+			return count, count <= 3
+		}),
+		node.ForEach(func(i int) {
+			res = append(res, i)
+		}),
+	).Start()
+
+	as.Nil(s.Wait())
+	as.Equal([]int{1, 2, 3}, res)
+	<-s.Done()
+	as.False(s.IsRunning())
+	as.ErrorIs(s.Stop(), stream.ErrAlreadyStopped)
+}
+
+func TestStreamWaitTake(t *testing.T) {
+	as := assert.New(t)
+
+	s := caravan.NewStream(
+		node.Generate(func() (any, bool) { return "hello", true }),
+		node.Take[any](5),
+	).Start()
+
+	as.Nil(s.Wait())
+	as.False(s.IsRunning())
+	as.ErrorIs(s.Stop(), stream.ErrAlreadyStopped)
+}
+
+func TestStreamWaitFatal(t *testing.T) {
+	as := assert.New(t)
+
+	errBoom := errors.New("boom")
+	s := caravan.NewStream(
+		node.Generate(func() (any, bool) { return "hello", true }),
+		func(c *context.Context[any, any]) {
+			c.Fatal(errBoom)
+		},
+	).Start()
+
+	as.ErrorIs(s.Wait(), errBoom)
+	as.False(s.IsRunning())
+}
+
+func TestStreamWaitFatalHandled(t *testing.T) {
+	as := assert.New(t)
+
+	errBoom := errors.New("boom")
+	s := caravan.NewStream(
+		node.Generate(func() (any, bool) { return "hello", true }),
+		func(c *context.Context[any, any]) {
+			c.Fatal(errBoom)
+		},
+	).StartWith(func(context.Advice, func()) {})
+
+	as.ErrorIs(s.Wait(), errBoom)
+	as.False(s.IsRunning())
+}
+
+func TestStreamWaitStopped(t *testing.T) {
+	as := assert.New(t)
+
+	s := makeGeneratingStream("hello").Start()
+	select {
+	case <-s.Done():
+		as.Fail("stream should still be running")
+	default:
+	}
+
+	as.Nil(s.Stop())
+	as.Nil(s.Wait())
+}
+
+func makePanickingStream(at int) stream.Stream {
+	count := 0
+	return caravan.NewStream(
+		node.Generate(func() (int, bool) {
+			count++
+			return count, true
+		}),
+		node.Map(func(i int) int {
+			if i == at {
+				panic("boom")
+			}
+			return i
+		}),
+	)
+}
+
+func TestStreamPanic(t *testing.T) {
+	as := assert.New(t)
+
+	s := makePanickingStream(3).Start()
+	err := s.Wait()
+	as.EqualError(err, "processor panicked: boom")
+
+	var p *context.PanicError
+	as.True(errors.As(err, &p))
+	as.Equal("boom", p.Value)
+	as.Contains(string(p.Stack), "panic")
+	as.False(s.IsRunning())
+}
+
+func TestStreamRestartNode(t *testing.T) {
+	as := assert.New(t)
+
+	count := 0
+	out := make(chan int)
+	s := caravan.NewStream(
+		node.Generate(func() (int, bool) {
+			count++
+			return count, true
+		}),
+		node.Map(func(i int) int {
+			if i == 2 {
+				panic("boom")
+			}
+			return i
+		}),
+		node.SidechainTo(out),
+	).With(stream.WithSupervision(stream.Supervision{
+		Policy: stream.RestartNode,
+	})).StartWith(func(a context.Advice, next func()) {
+		var p *context.PanicError
+		as.True(errors.As(a.(error), &p))
+	})
+
+	as.Equal(1, <-out)
+	as.Equal(3, <-out)
+	as.Equal(4, <-out)
+	as.True(s.IsRunning())
+	as.Nil(s.Stop())
+}
+
+func TestStreamRestartStream(t *testing.T) {
+	as := assert.New(t)
+
+	attempts := 0
+	reported := 0
+	s := caravan.NewStream(
+		func(c *context.Context[stream.Source, int]) {
+			attempts++
+			if attempts < 3 {
+				panic("boom")
+			}
+			c.ForwardResult(attempts)
+		},
+		node.Forward[int],
+	).With(stream.WithSupervision(stream.Supervision{
+		Policy:  stream.RestartStream,
+		Backoff: time.Millisecond,
+	})).StartWith(func(a context.Advice, _ func()) {
+		var p *context.PanicError
+		as.ErrorAs(a.(error), &p)
+		reported++
+	})
+
+	as.Nil(s.Wait())
+	as.Equal(3, attempts)
+	as.Equal(2, reported)
+	as.False(s.IsRunning())
+	as.ErrorIs(s.Stop(), stream.ErrAlreadyStopped)
+}
+
+func TestStreamRestartLimit(t *testing.T) {
+	as := assert.New(t)
+
+	attempts := 0
+	s := caravan.NewStream(
+		func(*context.Context[stream.Source, any]) {
+			attempts++
+			panic("boom")
+		},
+		node.Forward[any],
+	).With(stream.WithSupervision(stream.Supervision{
+		Policy:      stream.RestartStream,
+		MaxRestarts: 2,
+		Backoff:     time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	})).Start()
+
+	var p *context.PanicError
+	as.ErrorAs(s.Wait(), &p)
+	as.Equal(3, attempts)
+	as.False(s.IsRunning())
+}
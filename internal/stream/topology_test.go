@@ -0,0 +1,167 @@
+package stream_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+	"github.com/kode4food/caravan/stream/topology"
+)
+
+func TestTopology(t *testing.T) {
+	as := assert.New(t)
+
+	in := caravan.NewTopic[int]()
+	out := caravan.NewTopic[int]()
+	s := caravan.NewStream(
+		node.TopicConsumer(in),
+		node.Named("double", node.Map(func(i int) int { return i * 2 })),
+		node.Filter(func(i int) bool { return i > 2 }),
+		node.TopicProducer(out),
+	)
+
+	top := s.Topology()
+	as.Equal([]*topology.Node{
+		{ID: "/0", Kind: "TopicConsumer", In: "stream.Source", Out: "int"},
+		{ID: "/1/0", Name: "double", Kind: "Map", In: "int", Out: "int"},
+		{ID: "/1/1/0", Kind: "Filter", In: "int", Out: "int"},
+		{ID: "/1/1/1", Kind: "TopicProducer", In: "int", Out: "int"},
+	}, top.Nodes)
+	as.Equal([]*topology.Edge{
+		{From: "/0", To: "/1/0"},
+		{From: "/1/0", To: "/1/1/0"},
+		{From: "/1/1/0", To: "/1/1/1"},
+	}, top.Edges)
+}
+
+func TestTopologyFanInOut(t *testing.T) {
+	as := assert.New(t)
+
+	s := caravan.BasicStream(
+		node.Zip(
+			node.Merge(
+				node.Generate(func() (int, bool) { return 1, true }),
+				node.Generate(func() (int, bool) { return 2, true }),
+			),
+			node.Generate(func() (string, bool) { return "s", true }),
+		),
+		node.Split(
+			node.Named("left", node.Forward[node.Pair[int, string]]),
+			node.Named("right", node.Forward[node.Pair[int, string]]),
+		),
+	)
+
+	top := s.Topology()
+	kinds := map[string]string{}
+	for _, n := range top.Nodes {
+		kinds[n.ID] = n.Kind
+	}
+	as.Equal(map[string]string{
+		"/0":     "ZipWith",
+		"/0/0/0": "Generate",
+		"/0/0/1": "Generate",
+		"/0/1":   "Generate",
+		"/1":     "Split",
+		"/1/1/0": "Forward",
+		"/1/1/1": "Forward",
+	}, kinds)
+	as.ElementsMatch([]*topology.Edge{
+		{From: "/0/0/0", To: "/0"},
+		{From: "/0/0/1", To: "/0"},
+		{From: "/0/1", To: "/0"},
+		{From: "/0", To: "/1"},
+		{From: "/1", To: "/1/1/0"},
+		{From: "/1", To: "/1/1/1"},
+	}, top.Edges)
+}
+
+func TestTopologyExport(t *testing.T) {
+	as := assert.New(t)
+
+	s := caravan.NewStream(
+		node.Generate(func() (int, bool) { return 1, true }),
+		node.Named("count", node.Map(func(i int) int { return i })),
+	)
+
+	top := s.Topology()
+	as.Equal("digraph stream {\n"+
+		"\trankdir=LR;\n"+
+		"\t\"/0\" [label=\"Generate\\nstream.Source -> int\"];\n"+
+		"\t\"/1\" [label=\"count\\nMap\\nint -> int\"];\n"+
+		"\t\"/0\" -> \"/1\";\n"+
+		"}\n", top.DOT())
+
+	data, err := json.Marshal(top)
+	as.Nil(err)
+	as.JSONEq(`{
+		"nodes": [
+			{"id": "/0", "kind": "Generate", "in": "stream.Source", "out": "int"},
+			{"id": "/1", "name": "count", "kind": "Map", "in": "int", "out": "int"}
+		],
+		"edges": [{"from": "/0", "to": "/1"}]
+	}`, string(data))
+}
+
+func TestTopologyRunning(t *testing.T) {
+	as := assert.New(t)
+
+	out := make(chan int)
+	s := caravan.NewStream(
+		node.Generate(func() (int, bool) { return 1, true }),
+		node.SidechainTo(out),
+	)
+	as.Len(s.Topology().Nodes, 2)
+
+	// describing the topology has no effect on running the Stream
+	r := s.Start()
+	as.Equal(1, <-out)
+	as.Nil(r.Stop())
+	var _ stream.Running = r
+}
+
+func TestTopologyWithoutRunning(t *testing.T) {
+	as := assert.New(t)
+
+	called := false
+	s := caravan.NewStream(
+		node.Generate(func() (int, bool) { return 1, true }),
+		node.Named("custom", stream.Processor[int, int](
+			func(*context.Context[int, int]) {
+				called = true
+				select {} // would never return
+			},
+		)),
+	)
+	top := s.Topology()
+	as.False(called)
+	as.Equal([]*topology.Node{
+		{ID: "/0", Kind: "Generate", In: "stream.Source", Out: "int"},
+		{ID: "/1", Name: "custom", Kind: "TestTopologyWithoutRunning",
+			In: "int", Out: "int"},
+	}, top.Nodes)
+}
+
+func TestTopologyComposite(t *testing.T) {
+	as := assert.New(t)
+
+	twice := func(p stream.Processor[int, int]) stream.Processor[int, int] {
+		return stream.Composite(func(c *context.Context[int, int]) {
+			node.Bind(p, p).Start(c)
+		})
+	}
+	s := caravan.NewStream(
+		node.Generate(func() (int, bool) { return 1, true }),
+		twice(node.Map(func(i int) int { return i + 1 })),
+	)
+	top := s.Topology()
+	as.Equal([]*topology.Node{
+		{ID: "/0", Kind: "Generate", In: "stream.Source", Out: "int"},
+		{ID: "/1/0", Kind: "Map", In: "int", Out: "int"},
+		{ID: "/1/1", Kind: "Map", In: "int", Out: "int"},
+	}, top.Nodes)
+}
@@ -0,0 +1,121 @@
+package stream_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+	"github.com/kode4food/caravan/stream/trace"
+)
+
+type tracedInt = trace.Traced[int]
+
+func TestStreamTracing(t *testing.T) {
+	as := assert.New(t)
+
+	in := caravan.NewTopic[tracedInt]()
+	out := caravan.NewTopic[tracedInt]()
+	rec := trace.MakeRecorder()
+	r := caravan.NewStream(
+		node.TopicConsumer(in),
+		node.Named("double", node.Map(func(m tracedInt) tracedInt {
+			return trace.Trace(m.Msg*2, trace.SpanContext{})
+		})),
+		node.TopicProducer(out),
+	).With(stream.WithTracing(rec)).Start()
+	defer func() { _ = r.Stop() }()
+
+	p := in.NewProducer()
+	defer p.Close()
+	p.Send() <- trace.Trace(21, trace.SpanContext{})
+
+	c := out.NewConsumer()
+	defer c.Close()
+	res := <-c.Receive()
+	as.Equal(42, res.Msg)
+	as.True(res.Span.IsValid())
+
+	as.Nil(r.Stop())
+	<-r.Done()
+
+	spans := rec.Trace(res.Span.TraceID)
+	byID := map[string]*trace.RecordedSpan{}
+	for _, s := range spans {
+		byID[s.ID] = s
+	}
+	as.Len(byID, 3)
+	cons, dbl, prod := byID["/0"], byID["/1/0"], byID["/1/1"]
+	as.Equal("TopicConsumer", cons.Kind)
+	as.Equal(trace.SpanID{}, cons.Parent)
+	as.Equal("double", dbl.Name)
+	as.Equal(cons.Context.SpanID, dbl.Parent)
+	as.Equal(dbl.Context.SpanID, prod.Parent)
+	as.Equal(dbl.Context.SpanID, res.Span.SpanID)
+}
+
+func TestStreamTracingError(t *testing.T) {
+	as := assert.New(t)
+
+	err := errors.New("boom")
+	rec := trace.MakeRecorder()
+	i := 0
+	r := caravan.NewStream(
+		node.Generate(func() (int, bool) {
+			i++
+			return i, i <= 2
+		}),
+		func(c *context.Context[int, int]) {
+			for {
+				msg, ok := c.FetchMessage()
+				if !ok {
+					return
+				}
+				if msg == 2 {
+					c.Error(err)
+					continue
+				}
+				if !c.ForwardResult(msg) {
+					return
+				}
+			}
+		},
+	).With(stream.WithTracing(rec)).StartWith(func(context.Advice, func()) {})
+	as.Nil(r.Wait())
+
+	var errs []error
+	for _, s := range rec.Spans() {
+		if s.ID == "/1" {
+			errs = append(errs, s.Err)
+		}
+	}
+	as.Equal([]error{nil, err}, errs)
+}
+
+func TestStreamTracingEmptyMessages(t *testing.T) {
+	as := assert.New(t)
+
+	rec := trace.MakeRecorder()
+	i := 0
+	r := caravan.NewStream(
+		node.Generate(func() (struct{}, bool) {
+			i++
+			return struct{}{}, i <= 3
+		}),
+		node.Filter(func(struct{}) bool { return false }),
+	).With(stream.WithTracing(rec)).Start()
+	as.Nil(r.Wait())
+
+	// messages that carry no data aren't mistaken for those of a source
+	var filtered int
+	for _, s := range rec.Spans() {
+		if s.ID == "/1" {
+			filtered++
+		}
+	}
+	as.Equal(3, filtered)
+}
@@ -37,5 +37,7 @@ func (r *ReadyWait) Wait() <-chan struct{} {
 
 // Close closes the underlying ready channel
 func (r *ReadyWait) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	close(r.ready)
 }
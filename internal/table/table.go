@@ -2,9 +2,13 @@ package table
 
 import (
 	"fmt"
+	"iter"
+	"slices"
 	"sync"
 
+	topicImpl "github.com/kode4food/caravan/internal/topic"
 	"github.com/kode4food/caravan/table"
+	"github.com/kode4food/caravan/topic"
 )
 
 // Table is the internal implementation of a table.Table
@@ -12,6 +16,8 @@ type Table[Key comparable, Value any] struct {
 	indexes map[table.ColumnName]int
 	rows    map[Key][]Value
 	names   []table.ColumnName
+	changes topic.Topic[table.Change[Key, Value]]
+	log     topic.Producer[table.Change[Key, Value]]
 	mu      sync.RWMutex
 }
 
@@ -79,13 +85,19 @@ func (t *Table[Key, Value]) Setter(
 			)
 		}
 		e, ok := t.rows[k]
-		if !ok {
+		change := table.Change[Key, Value]{Kind: table.Updated, Key: k}
+		if ok {
+			change.Old = slices.Clone(e)
+		} else {
+			change.Kind = table.Inserted
 			e = make([]Value, len(t.names))
 		}
 		for in, out := range indexes {
 			e[out] = v[in]
 		}
 		t.rows[k] = e
+		change.New = slices.Clone(e)
+		t.produce(change)
 		return nil
 	}, nil
 }
@@ -106,10 +118,16 @@ func (t *Table[Key, Value]) Delete(k Key) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	if _, ok := t.rows[k]; !ok {
+	e, ok := t.rows[k]
+	if !ok {
 		return fmt.Errorf("%w: %v", table.ErrKeyNotFoundDelete, k)
 	}
 	delete(t.rows, k)
+	t.produce(table.Change[Key, Value]{
+		Kind: table.Deleted,
+		Key:  k,
+		Old:  e,
+	})
 	return nil
 }
 
@@ -144,6 +162,34 @@ func (t *Table[Key, Value]) Range(fn func(Key, []Value) bool) {
 	}
 }
 
+// All returns an iterator over all rows in the Table
+func (t *Table[Key, Value]) All() iter.Seq2[Key, []Value] {
+	return t.Range
+}
+
+// Changes returns the Topic that the Table's change log is produced to. The
+// Topic is created by the first call, and changes made before then aren't
+// produced
+func (t *Table[Key, Value]) Changes() topic.Topic[table.Change[Key, Value]] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.changes == nil {
+		t.changes = topicImpl.Make[table.Change[Key, Value]]()
+		t.log = t.changes.NewProducer()
+	}
+	return t.changes
+}
+
+// produce adds a Change to the change log, if it's been requested. It's
+// called with the Table's lock held, so that Changes are produced in the
+// order they were made
+func (t *Table[Key, Value]) produce(c table.Change[Key, Value]) {
+	if t.log != nil {
+		t.log.Send() <- c
+	}
+}
+
 func checkColumnDuplicates(c []table.ColumnName) error {
 	names := map[table.ColumnName]bool{}
 	for _, n := range c {
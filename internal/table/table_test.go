@@ -2,12 +2,14 @@ package table_test
 
 import (
 	"fmt"
+	"maps"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/kode4food/caravan"
 	"github.com/kode4food/caravan/table"
+	"github.com/kode4food/caravan/topic"
 )
 
 func TestTable(t *testing.T) {
@@ -268,3 +270,76 @@ func TestTableRangeEarlyExit(t *testing.T) {
 
 	as.Equal(3, count)
 }
+
+func TestTableAll(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := caravan.NewTable[string, any]("name", "age")
+	setter, _ := tbl.Setter("name", "age")
+
+	as.Nil(setter("user-1", "alice", 25))
+	as.Nil(setter("user-2", "bob", 30))
+
+	rows := maps.Collect(tbl.All())
+	as.Equal(map[string][]any{
+		"user-1": {"alice", 25},
+		"user-2": {"bob", 30},
+	}, rows)
+
+	count := 0
+	for range tbl.All() {
+		count++
+		break
+	}
+	as.Equal(1, count)
+}
+
+func TestTableChanges(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := caravan.NewTable[string, any]("name", "age")
+	setter, _ := tbl.Setter("name", "age")
+	ageSetter, _ := tbl.Setter("age")
+
+	// changes made before the log is requested aren't produced
+	as.Nil(setter("user-0", "zed", 99))
+
+	changes := tbl.Changes()
+	as.Equal(changes, tbl.Changes())
+	c1 := changes.NewConsumer()
+	defer c1.Close()
+	c2 := changes.NewConsumer()
+	defer c2.Close()
+
+	as.Nil(setter("user-1", "alice", 25))
+	as.Nil(ageSetter("user-1", 26))
+	as.Nil(tbl.Delete("user-1"))
+	as.NotNil(tbl.Delete("user-1"))
+
+	for _, c := range []topic.Consumer[table.Change[string, any]]{c1, c2} {
+		as.Equal(table.Change[string, any]{
+			Kind: table.Inserted,
+			Key:  "user-1",
+			New:  []any{"alice", 25},
+		}, <-c.Receive())
+		as.Equal(table.Change[string, any]{
+			Kind: table.Updated,
+			Key:  "user-1",
+			Old:  []any{"alice", 25},
+			New:  []any{"alice", 26},
+		}, <-c.Receive())
+		as.Equal(table.Change[string, any]{
+			Kind: table.Deleted,
+			Key:  "user-1",
+			Old:  []any{"alice", 26},
+		}, <-c.Receive())
+	}
+}
+
+func TestChangeKind(t *testing.T) {
+	as := assert.New(t)
+	as.Equal("inserted", table.Inserted.String())
+	as.Equal("updated", table.Updated.String())
+	as.Equal("deleted", table.Deleted.String())
+	as.Equal("unknown", table.ChangeKind(99).String())
+}
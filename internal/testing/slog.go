@@ -3,11 +3,13 @@ package testing
 import (
 	"context"
 	"log/slog"
+	"slices"
 )
 
 type TestSlogHandler struct {
 	Logs     chan slog.Record
 	minLevel slog.Leveler
+	attrs    []slog.Attr
 }
 
 func NewTestSlogHandler() *TestSlogHandler {
@@ -24,6 +26,8 @@ func (h *TestSlogHandler) Enabled(_ context.Context, level slog.Level) bool {
 }
 
 func (h *TestSlogHandler) Handle(_ context.Context, r slog.Record) error {
+	r = r.Clone()
+	r.AddAttrs(h.attrs...)
 	select {
 	case h.Logs <- r:
 	default:
@@ -31,8 +35,10 @@ func (h *TestSlogHandler) Handle(_ context.Context, r slog.Record) error {
 	return nil
 }
 
-func (h *TestSlogHandler) WithAttrs(_ []slog.Attr) slog.Handler {
-	return h
+func (h *TestSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := *h
+	res.attrs = append(slices.Clone(h.attrs), attrs...)
+	return &res
 }
 
 func (h *TestSlogHandler) WithGroup(_ string) slog.Handler {
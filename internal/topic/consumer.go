@@ -8,9 +8,11 @@ import (
 	"github.com/google/uuid"
 )
 
-type consumer[Msg any] struct {
+// consumer delivers the messages of a cursor as values of type Out, which
+// are either the messages themselves or Entries that include their offsets
+type consumer[Msg, Out any] struct {
 	*cursor[Msg]
-	channel chan Msg
+	channel chan Out
 	id      uuid.UUID
 }
 
@@ -18,22 +20,26 @@ var (
 	ErrConsumerNotClosed = errors.New("consumer not closed")
 )
 
-func makeConsumer[Msg any](c *cursor[Msg]) *consumer[Msg] {
-	res := &consumer[Msg]{
+func makeConsumer[Msg, Out any](
+	c *cursor[Msg], wrap func(uint64, Msg) Out,
+) *consumer[Msg, Out] {
+	res := &consumer[Msg, Out]{
 		cursor:  c,
 		id:      c.id,
-		channel: startConsumer(c),
+		channel: startConsumer(c, wrap),
 	}
-	runtime.SetFinalizer(res, consumerDebugFinalizer[Msg])
+	runtime.SetFinalizer(res, consumerDebugFinalizer[Msg, Out])
 	return res
 }
 
-func (c *consumer[Msg]) Receive() <-chan Msg {
+func (c *consumer[_, Out]) Receive() <-chan Out {
 	return c.channel
 }
 
-func startConsumer[Msg any](c *cursor[Msg]) chan Msg {
-	ch := make(chan Msg)
+func startConsumer[Msg, Out any](
+	c *cursor[Msg], wrap func(uint64, Msg) Out,
+) chan Out {
+	ch := make(chan Out)
 	go func() {
 		defer func() {
 			// probably because the channel was closed
@@ -48,7 +54,7 @@ func startConsumer[Msg any](c *cursor[Msg]) chan Msg {
 					select {
 					case <-c.IsClosed():
 						goto closed
-					case ch <- e:
+					case ch <- wrap(c.position(), e):
 						c.advance()
 					}
 				} else {
@@ -67,7 +73,7 @@ func startConsumer[Msg any](c *cursor[Msg]) chan Msg {
 	return ch
 }
 
-func consumerDebugFinalizer[Msg any](c *consumer[Msg]) {
+func consumerDebugFinalizer[Msg, Out any](c *consumer[Msg, Out]) {
 	select {
 	case <-c.IsClosed():
 	default:
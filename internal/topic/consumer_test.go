@@ -12,6 +12,7 @@ import (
 	"github.com/kode4food/caravan/closer"
 	testutil "github.com/kode4food/caravan/internal/testing"
 	"github.com/kode4food/caravan/message"
+	"github.com/kode4food/caravan/topic"
 )
 
 func TestConsumerClosed(t *testing.T) {
@@ -211,3 +212,29 @@ func TestConsumerChannelClosed(t *testing.T) {
 	_, ok := <-ch
 	as.False(ok)
 }
+
+func TestConsumerFrom(t *testing.T) {
+	as := assert.New(t)
+
+	top := caravan.NewTopic[string]()
+	p := top.NewProducer()
+	defer p.Close()
+	for _, s := range []string{"zero", "one", "two"} {
+		p.Send() <- s
+	}
+
+	c := top.NewConsumerFrom(1)
+	defer c.Close()
+	as.Equal(
+		topic.Entry[string]{Offset: 1, Message: "one"}, message.MustReceive(c),
+	)
+	as.Equal(
+		topic.Entry[string]{Offset: 2, Message: "two"}, message.MustReceive(c),
+	)
+
+	p.Send() <- "three"
+	as.Equal(
+		topic.Entry[string]{Offset: 3, Message: "three"},
+		message.MustReceive(c),
+	)
+}
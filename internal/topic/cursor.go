@@ -40,9 +40,9 @@ func makeCursor[Msg any](t *Topic[Msg]) *cursor[Msg] {
 		topic: t,
 		ready: ready,
 		Closer: makeCloser(func() {
-			ready.Close()
-			t.cursors.remove(cID)
 			t.observers.remove(cID)
+			t.cursors.remove(cID)
+			ready.Close()
 		}),
 	}
 	runtime.SetFinalizer(res, cursorFinalizer[Msg])
@@ -59,6 +59,10 @@ func (c *cursor[Msg]) head() (Msg, bool) {
 	return zero, false
 }
 
+func (c *cursor[_]) position() uint64 {
+	return atomic.LoadUint64(&c.offset)
+}
+
 func (c *cursor[_]) advance() {
 	atomic.AddUint64(&c.offset, 1)
 }
@@ -1,6 +1,7 @@
 package topic
 
 import (
+	"iter"
 	"sync"
 
 	"github.com/google/uuid"
@@ -57,7 +58,48 @@ func (t *Topic[Msg]) NewProducer() topic.Producer[Msg] {
 
 // NewConsumer instantiates a new Topic Consumer
 func (t *Topic[Msg]) NewConsumer() topic.Consumer[Msg] {
-	return makeConsumer(t.makeCursor())
+	return makeConsumer(t.makeCursor(), func(_ uint64, msg Msg) Msg {
+		return msg
+	})
+}
+
+// NewConsumerFrom instantiates a new Topic Consumer that receives Entries,
+// beginning at the specified virtual offset. If the offset is no longer being
+// retained, the Consumer begins with the next available offset
+func (t *Topic[Msg]) NewConsumerFrom(
+	offset uint64,
+) topic.Consumer[topic.Entry[Msg]] {
+	return makeConsumer(t.makeCursorAt(offset),
+		func(off uint64, msg Msg) topic.Entry[Msg] {
+			return topic.Entry[Msg]{
+				Offset:  off,
+				Message: msg,
+			}
+		},
+	)
+}
+
+// Retained returns an iterator over the messages currently retained by the
+// Topic, paired with their virtual offsets. Messages produced after iteration
+// begins are not included
+func (t *Topic[Msg]) Retained() iter.Seq2[uint64, Msg] {
+	return func(yield func(uint64, Msg) bool) {
+		end := t.Length()
+		c := t.makeCursor()
+		defer c.Close()
+
+		for {
+			e, ok := c.head()
+			if !ok {
+				return
+			}
+			off := c.position()
+			if off >= end || !yield(off, e) {
+				return
+			}
+			c.advance()
+		}
+	}
 }
 
 // get consumes a message starting at the specified virtual Offset within the
@@ -111,7 +153,12 @@ func (t *Topic[Msg]) vacuum() {
 }
 
 func (t *Topic[Msg]) makeCursor() *cursor[Msg] {
+	return t.makeCursorAt(0)
+}
+
+func (t *Topic[Msg]) makeCursorAt(offset uint64) *cursor[Msg] {
 	c := makeCursor(t)
+	c.offset = offset
 	t.cursors.track(c)
 	t.observers.add(c.id, c.ready.Notify)
 	return c
@@ -1,12 +1,15 @@
 package topic_test
 
 import (
+	"maps"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/topic"
 )
 
 func TestLongLog(t *testing.T) {
@@ -105,3 +108,64 @@ func TestLogDiscardEverything(t *testing.T) {
 	e := <-c.Receive()
 	as.Equal(segmentSize, e)
 }
+
+func TestReplay(t *testing.T) {
+	as := assert.New(t)
+
+	l := caravan.NewTopic[int]()
+	p := l.NewProducer()
+	defer p.Close()
+
+	for i := range 10 {
+		p.Send() <- i * 10
+	}
+	as.Eventually(func() bool {
+		return l.Length() == 10
+	}, time.Second, time.Millisecond)
+
+	res := maps.Collect(topic.Replay(l))
+	as.Equal(10, len(res))
+	for i := range 10 {
+		as.Equal(i*10, res[uint64(i)])
+	}
+
+	var offsets []uint64
+	for o := range topic.Replay(l) {
+		offsets = append(offsets, o)
+		if o == 2 {
+			break
+		}
+	}
+	as.Equal([]uint64{0, 1, 2}, offsets)
+}
+
+func TestReplayDiscarded(t *testing.T) {
+	as := assert.New(t)
+
+	segmentSize := 256
+	l := caravan.NewTopic[int]()
+	p := l.NewProducer()
+	defer p.Close()
+
+	c := l.NewConsumer()
+	defer c.Close()
+
+	for i := range segmentSize + 3 {
+		p.Send() <- i
+	}
+	for range segmentSize + 1 {
+		<-c.Receive()
+	}
+
+	as.Eventually(func() bool {
+		offsets := slices.Collect(maps.Keys(maps.Collect(topic.Replay(l))))
+		slices.Sort(offsets)
+		return slices.Equal([]uint64{256, 257, 258}, offsets)
+	}, time.Second, time.Millisecond)
+}
+
+func TestReplayEmpty(t *testing.T) {
+	as := assert.New(t)
+	l := caravan.NewTopic[int]()
+	as.Empty(maps.Collect(topic.Replay(l)))
+}
@@ -0,0 +1,228 @@
+package message
+
+import (
+	"sync"
+
+	"github.com/kode4food/caravan/closer"
+)
+
+type (
+	// receiver is the ClosingReceiver returned by the fan-in and fan-out
+	// helpers. Its channel is closed once it stops producing messages
+	receiver[Msg any] struct {
+		*closing
+		channel chan Msg
+	}
+
+	// sender is the ClosingSender returned by the fan-out helpers. Its
+	// channel is closed when the sender is closed
+	sender[Msg any] struct {
+		*closing
+		channel chan Msg
+	}
+
+	closing struct {
+		channel chan struct{}
+		close   func()
+		once    sync.Once
+	}
+)
+
+// Merge returns a ClosingReceiver that forwards the messages of all provided
+// Receivers. Its channel is closed once all of the Receivers have been closed.
+// Closing the merged Receiver stops forwarding, but does not close the
+// Receivers that were provided
+func Merge[Msg any](r ...Receiver[Msg]) ClosingReceiver[Msg] {
+	res := makeReceiver[Msg]()
+	var group sync.WaitGroup
+	group.Add(len(r))
+	for _, in := range r {
+		go func() {
+			defer group.Done()
+			for {
+				m, ok := receiveOpen(res, in)
+				if !ok || !forward(res, res.channel, m) {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		group.Wait()
+		res.Close()
+		close(res.channel)
+	}()
+	return res
+}
+
+// Tee returns n ClosingReceivers that each receive every message from the
+// provided Receiver. A message is only considered delivered once all open
+// Receivers have accepted it. When the provided Receiver is closed, all of
+// the returned Receivers are closed as well
+func Tee[Msg any](r Receiver[Msg], n int) []ClosingReceiver[Msg] {
+	outs := make([]*receiver[Msg], n)
+	res := make([]ClosingReceiver[Msg], n)
+	for i := range outs {
+		outs[i] = makeReceiver[Msg]()
+		res[i] = outs[i]
+	}
+	all := makeClosing(nil)
+	onAllClosed(all, res, all.Close)
+	go func() {
+		defer func() {
+			all.Close()
+			for _, out := range outs {
+				out.Close()
+				close(out.channel)
+			}
+		}()
+		for {
+			m, ok := receiveOpen(all, r)
+			if !ok {
+				return
+			}
+			var group sync.WaitGroup
+			group.Add(n)
+			for _, out := range outs {
+				go func() {
+					defer group.Done()
+					forward(out, out.channel, m)
+				}()
+			}
+			group.Wait()
+		}
+	}()
+	return res
+}
+
+// Broadcast returns a ClosingSender that delivers every message it is sent
+// to all of the provided Senders that remain open. It is closed automatically
+// once all of the provided Senders have been closed
+func Broadcast[Msg any](s ...ClosingSender[Msg]) ClosingSender[Msg] {
+	return makeSender(s, func(m Msg) {
+		var group sync.WaitGroup
+		group.Add(len(s))
+		for _, out := range s {
+			go func() {
+				defer group.Done()
+				Send(out, m)
+			}()
+		}
+		group.Wait()
+	})
+}
+
+// RoundRobin returns a ClosingSender that delivers each message it is sent
+// to the next of the provided Senders, skipping any that have been closed.
+// It is closed automatically once all of the provided Senders have been
+// closed
+func RoundRobin[Msg any](s ...ClosingSender[Msg]) ClosingSender[Msg] {
+	next := 0
+	return makeSender(s, func(m Msg) {
+		for range s {
+			out := s[next]
+			next = (next + 1) % len(s)
+			if Send(out, m) {
+				return
+			}
+		}
+	})
+}
+
+func makeReceiver[Msg any]() *receiver[Msg] {
+	return &receiver[Msg]{
+		closing: makeClosing(nil),
+		channel: make(chan Msg),
+	}
+}
+
+func (r *receiver[Msg]) Receive() <-chan Msg {
+	return r.channel
+}
+
+func makeSender[Msg any](
+	s []ClosingSender[Msg], deliver func(Msg),
+) ClosingSender[Msg] {
+	ch := make(chan Msg)
+	res := &sender[Msg]{
+		closing: makeClosing(func() {
+			close(ch)
+		}),
+		channel: ch,
+	}
+	onAllClosed(res, s, res.Close)
+	go func() {
+		for m := range ch {
+			deliver(m)
+		}
+	}()
+	return res
+}
+
+func (s *sender[Msg]) Send() chan<- Msg {
+	return s.channel
+}
+
+func makeClosing(close func()) *closing {
+	return &closing{
+		channel: make(chan struct{}),
+		close:   close,
+	}
+}
+
+func (c *closing) Close() {
+	c.once.Do(func() {
+		close(c.channel)
+		if c.close != nil {
+			c.close()
+		}
+	})
+}
+
+func (c *closing) IsClosed() <-chan struct{} {
+	return c.channel
+}
+
+// onAllClosed calls the provided function once all the provided Closers have
+// been closed, unless the stop Closer is closed first
+func onAllClosed[C closer.Closer](stop closer.Closer, c []C, fn func()) {
+	go func() {
+		for _, e := range c {
+			select {
+			case <-stop.IsClosed():
+				return
+			case <-e.IsClosed():
+			}
+		}
+		fn()
+	}()
+}
+
+// receiveOpen receives the next message from a Receiver, giving up when
+// either the Receiver or the provided Closer have been closed
+func receiveOpen[Msg any](c closer.Closer, r Receiver[Msg]) (Msg, bool) {
+	var zero Msg
+	var closed <-chan struct{}
+	if rc, ok := r.(closer.Closer); ok {
+		closed = rc.IsClosed()
+	}
+	select {
+	case <-c.IsClosed():
+		return zero, false
+	case <-closed:
+		return zero, false
+	case m, ok := <-r.Receive():
+		return m, ok
+	}
+}
+
+// forward sends a message to a channel, giving up when the provided Closer
+// has been closed
+func forward[Msg any](c closer.Closer, ch chan<- Msg, m Msg) bool {
+	select {
+	case <-c.IsClosed():
+		return false
+	case ch <- m:
+		return true
+	}
+}
@@ -0,0 +1,152 @@
+package message_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/closer"
+	"github.com/kode4food/caravan/message"
+)
+
+func TestMerge(t *testing.T) {
+	as := assert.New(t)
+	left := make(chan int)
+	right := caravan.NewTopic[int]()
+	p := right.NewProducer()
+	defer p.Close()
+	c := right.NewConsumer()
+
+	m := message.Merge[int](chanReceiver[int](left), c)
+	go func() {
+		left <- 1
+		left <- 2
+		close(left)
+		message.Send(p, 3)
+	}()
+
+	var res []int
+	for range 3 {
+		res = append(res, message.MustReceive(m))
+	}
+	slices.Sort(res)
+	as.Equal([]int{1, 2, 3}, res)
+	as.False(closer.IsClosed(m))
+
+	c.Close()
+	_, ok := <-m.Receive()
+	as.False(ok)
+	as.True(closer.IsClosed(m))
+}
+
+func TestMergeClosed(t *testing.T) {
+	as := assert.New(t)
+	top := caravan.NewTopic[int]()
+	c := top.NewConsumer()
+	defer c.Close()
+
+	m := message.Merge[int](c)
+	m.Close()
+	_, ok := <-m.Receive()
+	as.False(ok)
+	as.False(closer.IsClosed(c))
+}
+
+func TestTee(t *testing.T) {
+	as := assert.New(t)
+	top := caravan.NewTopic[int]()
+	p := top.NewProducer()
+	defer p.Close()
+	c := top.NewConsumer()
+
+	outs := message.Tee[int](c, 2)
+	as.Len(outs, 2)
+	for i := range 3 {
+		message.Send(p, i)
+	}
+
+	for i := range 3 {
+		as.Equal(i, message.MustReceive(outs[1]))
+		as.Equal(i, message.MustReceive(outs[0]))
+	}
+
+	outs[0].Close()
+	message.Send(p, 3)
+	as.Equal(3, message.MustReceive(outs[1]))
+
+	c.Close()
+	_, ok := <-outs[1].Receive()
+	as.False(ok)
+	as.True(closer.IsClosed(outs[1]))
+}
+
+func TestBroadcast(t *testing.T) {
+	as := assert.New(t)
+	first := caravan.NewTopic[string]()
+	second := caravan.NewTopic[string]()
+	fp := first.NewProducer()
+	sp := second.NewProducer()
+
+	b := message.Broadcast(fp, sp)
+	as.True(message.Send(b, "hello"))
+
+	fc := first.NewConsumer()
+	defer fc.Close()
+	sc := second.NewConsumer()
+	defer sc.Close()
+	as.Equal("hello", message.MustReceive(fc))
+	as.Equal("hello", message.MustReceive(sc))
+
+	fp.Close()
+	as.True(message.Send(b, "world"))
+	as.Equal("world", message.MustReceive(sc))
+	as.False(closer.IsClosed(b))
+
+	sp.Close()
+	as.Eventually(func() bool {
+		return closer.IsClosed(b)
+	}, time.Second, time.Millisecond)
+	as.False(message.Send(b, "closed"))
+}
+
+func TestRoundRobin(t *testing.T) {
+	as := assert.New(t)
+	first := caravan.NewTopic[int]()
+	second := caravan.NewTopic[int]()
+	fp := first.NewProducer()
+	sp := second.NewProducer()
+	defer sp.Close()
+
+	rr := message.RoundRobin(fp, sp)
+	for i := range 4 {
+		as.True(message.Send(rr, i))
+	}
+
+	fc := first.NewConsumer()
+	defer fc.Close()
+	sc := second.NewConsumer()
+	defer sc.Close()
+	as.Equal(0, message.MustReceive(fc))
+	as.Equal(2, message.MustReceive(fc))
+	as.Equal(1, message.MustReceive(sc))
+	as.Equal(3, message.MustReceive(sc))
+
+	fp.Close()
+	as.True(message.Send(rr, 4))
+	as.True(message.Send(rr, 5))
+	as.Equal(4, message.MustReceive(sc))
+	as.Equal(5, message.MustReceive(sc))
+
+	rr.Close()
+	as.False(message.Send(rr, 6))
+}
+
+// chanReceiver adapts a plain channel to the Receiver interface
+type chanReceiver[Msg any] chan Msg
+
+func (c chanReceiver[Msg]) Receive() <-chan Msg {
+	return c
+}
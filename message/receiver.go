@@ -1,7 +1,9 @@
 package message
 
 import (
+	"context"
 	"errors"
+	"iter"
 	"time"
 
 	"github.com/kode4food/caravan/closer"
@@ -27,13 +29,10 @@ var (
 // Poll will wait up until the specified Duration for a message to possibly be
 // returned, advancing the Receiver's Cursor upon success
 func Poll[Msg any](r Receiver[Msg], d time.Duration) (Msg, bool) {
-	select {
-	case <-time.After(d):
-		var zero Msg
-		return zero, false
-	case m, ok := <-r.Receive():
-		return m, ok
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	m, err := ReceiveContext(ctx, r)
+	return m, err == nil
 }
 
 // Receive returns the next message, blocking indefinitely, and advancing the
@@ -43,6 +42,58 @@ func Receive[Msg any](r Receiver[Msg]) (Msg, bool) {
 	return m, ok
 }
 
+// ReceiveContext returns the next message, blocking until one is available or
+// the provided Context is done. If the Context is done, its error is returned.
+// If the Receiver is closed, ErrReceiverClosed is returned
+func ReceiveContext[Msg any](
+	ctx context.Context, r Receiver[Msg],
+) (Msg, error) {
+	select {
+	case <-ctx.Done():
+		var zero Msg
+		return zero, ctx.Err()
+	case m, ok := <-r.Receive():
+		if !ok {
+			return m, ErrReceiverClosed
+		}
+		return m, nil
+	}
+}
+
+// ReceiveBatchContext receives up to n messages, blocking until all of them
+// have arrived or the provided Context is done. The messages received so far
+// are returned along with any error encountered
+func ReceiveBatchContext[Msg any](
+	ctx context.Context, r Receiver[Msg], n int,
+) ([]Msg, error) {
+	res := make([]Msg, 0, n)
+	for len(res) < n {
+		m, err := ReceiveContext(ctx, r)
+		if err != nil {
+			return res, err
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+// All returns an iterator that yields messages from the Receiver until it is
+// closed or the provided Context is canceled
+func All[Msg any](ctx context.Context, r Receiver[Msg]) iter.Seq[Msg] {
+	return func(yield func(Msg) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-r.Receive():
+				if !ok || !yield(m) {
+					return
+				}
+			}
+		}
+	}
+}
+
 // MustReceive will receive from a Receiver or panic if it is closed
 func MustReceive[Msg any](r Receiver[Msg]) Msg {
 	if m, ok := Receive(r); ok {
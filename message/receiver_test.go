@@ -1,6 +1,8 @@
 package message_test
 
 import (
+	"context"
+	"slices"
 	"testing"
 	"time"
 
@@ -42,3 +44,102 @@ func TestMustReceive(t *testing.T) {
 	}()
 	message.MustReceive(c)
 }
+
+func TestAll(t *testing.T) {
+	as := assert.New(t)
+	top := caravan.NewTopic[int]()
+	p := top.NewProducer()
+	for i := range 5 {
+		message.Send(p, i)
+	}
+	p.Close()
+
+	c := top.NewConsumer()
+	defer c.Close()
+
+	var res []int
+	for m := range message.All(context.Background(), c) {
+		res = append(res, m)
+		if len(res) == 3 {
+			break
+		}
+	}
+	as.Equal([]int{0, 1, 2}, res)
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), 10*time.Millisecond,
+	)
+	defer cancel()
+	as.Equal([]int{3, 4}, slices.Collect(message.All(ctx, c)))
+}
+
+func TestAllCanceled(t *testing.T) {
+	as := assert.New(t)
+	top := caravan.NewTopic[int]()
+	c := top.NewConsumer()
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), 10*time.Millisecond,
+	)
+	defer cancel()
+	as.Empty(slices.Collect(message.All(ctx, c)))
+}
+
+func TestAllClosed(t *testing.T) {
+	as := assert.New(t)
+	top := caravan.NewTopic[int]()
+	c := top.NewConsumer()
+	c.Close()
+	as.Empty(slices.Collect(message.All(context.Background(), c)))
+}
+
+func TestReceiveContext(t *testing.T) {
+	as := assert.New(t)
+	top := caravan.NewTopic[string]()
+	p := top.NewProducer()
+	message.Send(p, "hello")
+	p.Close()
+
+	c := top.NewConsumer()
+	m, err := message.ReceiveContext(context.Background(), c)
+	as.Equal("hello", m)
+	as.Nil(err)
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), 10*time.Millisecond,
+	)
+	defer cancel()
+	m, err = message.ReceiveContext(ctx, c)
+	as.Empty(m)
+	as.ErrorIs(err, context.DeadlineExceeded)
+
+	c.Close()
+	_, err = message.ReceiveContext(context.Background(), c)
+	as.ErrorIs(err, message.ErrReceiverClosed)
+}
+
+func TestReceiveBatchContext(t *testing.T) {
+	as := assert.New(t)
+	top := caravan.NewTopic[int]()
+	p := top.NewProducer()
+	for i := range 5 {
+		message.Send(p, i)
+	}
+	p.Close()
+
+	c := top.NewConsumer()
+	defer c.Close()
+
+	res, err := message.ReceiveBatchContext(context.Background(), c, 3)
+	as.Equal([]int{0, 1, 2}, res)
+	as.Nil(err)
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), 10*time.Millisecond,
+	)
+	defer cancel()
+	res, err = message.ReceiveBatchContext(ctx, c, 3)
+	as.Equal([]int{3, 4}, res)
+	as.ErrorIs(err, context.DeadlineExceeded)
+}
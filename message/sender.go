@@ -1,7 +1,9 @@
 package message
 
 import (
+	"context"
 	"errors"
+	"runtime"
 
 	"github.com/kode4food/caravan/closer"
 )
@@ -25,13 +27,62 @@ var (
 
 // Send sends a message to a ClosingSender
 func Send[Msg any](s ClosingSender[Msg], m Msg) bool {
+	return SendContext(context.Background(), s, m) == nil
+}
+
+// SendContext sends a message to a ClosingSender, blocking until it has been
+// accepted or the provided Context is done. If the Context is done, its error
+// is returned. If the Sender is closed, ErrSenderClosed is returned
+func SendContext[Msg any](
+	ctx context.Context, s ClosingSender[Msg], m Msg,
+) error {
+	if closer.IsClosed(s) {
+		return ErrSenderClosed
+	}
+	return sendOn(ctx, s, s.Send(), m)
+}
+
+// sendOn performs the send for SendContext. A panic caused by the channel
+// having been closed between the check and the send is reported as
+// ErrSenderClosed, while any other panic is propagated
+func sendOn[Msg any](
+	ctx context.Context, s closer.Closer, ch chan<- Msg, m Msg,
+) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if !isClosedChannelPanic(r) {
+				panic(r)
+			}
+			err = ErrSenderClosed
+		}
+	}()
 	select {
+	case <-ctx.Done():
+		return ctx.Err()
 	case <-s.IsClosed():
-		return false
-	default:
-		s.Send() <- m
-		return true
+		return ErrSenderClosed
+	case ch <- m:
+		return nil
+	}
+}
+
+func isClosedChannelPanic(r any) bool {
+	e, ok := r.(runtime.Error)
+	return ok && e.Error() == "send on closed channel"
+}
+
+// SendBatchContext sends each of the provided messages in order, stopping at
+// the first failure. The number of messages sent is returned along with any
+// error encountered
+func SendBatchContext[Msg any](
+	ctx context.Context, s ClosingSender[Msg], m []Msg,
+) (int, error) {
+	for i, msg := range m {
+		if err := SendContext(ctx, s, msg); err != nil {
+			return i, err
+		}
 	}
+	return len(m), nil
 }
 
 // MustSend will send to a ClosingSender or panic if it is closed
@@ -1,7 +1,9 @@
 package message_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -21,3 +23,128 @@ func TestMustSend(t *testing.T) {
 	}()
 	message.MustSend(p, "explode")
 }
+
+// blockedSender is a ClosingSender whose channel is never read
+type blockedSender struct {
+	channel chan string
+	closed  chan struct{}
+}
+
+func (s *blockedSender) Send() chan<- string {
+	return s.channel
+}
+
+func (s *blockedSender) Close() {
+	close(s.closed)
+}
+
+func (s *blockedSender) IsClosed() <-chan struct{} {
+	return s.closed
+}
+
+func TestSendClosedWhileBlocked(t *testing.T) {
+	as := assert.New(t)
+	s := &blockedSender{
+		channel: make(chan string),
+		closed:  make(chan struct{}),
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		s.Close()
+	}()
+	as.False(message.Send[string](s, "hello"))
+}
+
+func TestSendContext(t *testing.T) {
+	as := assert.New(t)
+	top := caravan.NewTopic[string]()
+	p := top.NewProducer()
+	as.Nil(message.SendContext(context.Background(), p, "hello"))
+
+	c := top.NewConsumer()
+	as.Equal("hello", message.MustReceive(c))
+	c.Close()
+
+	p.Close()
+	as.ErrorIs(
+		message.SendContext(context.Background(), p, "closed"),
+		message.ErrSenderClosed,
+	)
+}
+
+func TestSendContextCanceled(t *testing.T) {
+	as := assert.New(t)
+	s := &blockedSender{
+		channel: make(chan string),
+		closed:  make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), 10*time.Millisecond,
+	)
+	defer cancel()
+	as.ErrorIs(
+		message.SendContext[string](ctx, s, "hello"),
+		context.DeadlineExceeded,
+	)
+}
+
+func TestSendBatchContext(t *testing.T) {
+	as := assert.New(t)
+	top := caravan.NewTopic[int]()
+	p := top.NewProducer()
+
+	n, err := message.SendBatchContext(context.Background(), p, []int{1, 2, 3})
+	as.Equal(3, n)
+	as.Nil(err)
+
+	c := top.NewConsumer()
+	defer c.Close()
+	res, _ := message.ReceiveBatchContext(context.Background(), c, 3)
+	as.Equal([]int{1, 2, 3}, res)
+
+	p.Close()
+	n, err = message.SendBatchContext(context.Background(), p, []int{4, 5})
+	as.Equal(0, n)
+	as.ErrorIs(err, message.ErrSenderClosed)
+}
+
+// panickingSender is a ClosingSender whose Send method panics
+type panickingSender struct {
+	blockedSender
+}
+
+func (s *panickingSender) Send() chan<- string {
+	panic("sender exploded")
+}
+
+func TestSendContextClosedChannel(t *testing.T) {
+	as := assert.New(t)
+	s := &blockedSender{
+		channel: make(chan string),
+		closed:  make(chan struct{}),
+	}
+
+	close(s.channel)
+	as.ErrorIs(
+		message.SendContext[string](context.Background(), s, "hello"),
+		message.ErrSenderClosed,
+	)
+}
+
+func TestSendContextPanic(t *testing.T) {
+	as := assert.New(t)
+	s := &panickingSender{
+		blockedSender: blockedSender{
+			channel: make(chan string),
+			closed:  make(chan struct{}),
+		},
+	}
+
+	defer func() {
+		as.Equal("sender exploded", recover())
+	}()
+	_ = message.SendContext[string](context.Background(), s, "hello")
+	as.Fail("SendContext should have panicked")
+}
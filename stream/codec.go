@@ -0,0 +1,25 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// GobCodec is a Codec for Checkpointing that encodes the state of a Stream's
+// Processors using encoding/gob. Like any gob value, the messages held by the
+// Processors must have exported fields
+type GobCodec struct{}
+
+// Encode returns the gob encoding of the provided value
+func (GobCodec) Encode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode decodes the gob encoded data into the provided pointer
+func (GobCodec) Decode(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
@@ -0,0 +1,120 @@
+package context
+
+import (
+	"sync"
+
+	"github.com/kode4food/caravan/stream/metrics"
+)
+
+// WithBuffer returns a copy of the Context whose composite Processors, such as
+// node.Bind, buffer the channels between the Processors they start to hold
+// the provided number of messages
+func WithBuffer[In, Out any](
+	c *Context[In, Out], size int,
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.buffer = max(0, size)
+	return res
+}
+
+// Buffer returns the number of messages that the channels created by the
+// Context's composite Processor should hold
+func (c *Context[_, _]) Buffer() int {
+	return c.buffer
+}
+
+// WithQueue returns a copy of the Context whose In channel receives the
+// messages sent on the returned channel, holding up to the Context's Buffer
+// of them while its Processor is busy. If the Context is checkpointing, its
+// Barriers are held in the same queue as the messages, so that they're
+// received in the order they arrived. Barriers still queued once the
+// Processor has returned are passed downstream in its place
+func WithQueue[In, OldIn, Out any](
+	c *Context[OldIn, Out],
+) (*Context[In, Out], chan In) {
+	if c.buffer == 0 || c.barrierIn == nil || c.Describing() {
+		ch := make(chan In, c.buffer)
+		return derive(c, c.Done, ch, c.Out), ch
+	}
+
+	type item struct {
+		msg     In
+		barrier *Barrier
+	}
+
+	in := make(chan In)
+	out := make(chan In)
+	barriers := make(chan *Barrier)
+	queue := make(chan item, c.buffer)
+	gone := make(chan Done)
+
+	res := derive(c, c.Done, out, c.Out)
+	res.barrierIn = barriers
+	res.queue = func() (int, int) {
+		return len(queue), cap(queue)
+	}
+	var once sync.Once
+	res.group = makeGroup(c.group, func() {
+		once.Do(func() { close(gone) })
+	})
+
+	go func() {
+		defer close(queue)
+		for {
+			var i item
+			select {
+			case <-c.Done:
+				return
+			case i.barrier = <-c.barrierIn:
+			case msg, ok := <-in:
+				if !ok {
+					return
+				}
+				i.msg = msg
+			}
+			select {
+			case <-c.Done:
+				return
+			case queue <- i:
+			}
+		}
+	}()
+
+	go func() {
+		for i := range queue {
+			if i.barrier == nil {
+				select {
+				case <-c.Done:
+					return
+				case <-gone:
+				case out <- i.msg:
+				}
+				continue
+			}
+			select {
+			case <-c.Done:
+				return
+			case <-gone:
+				if !c.Checkpoint(i.barrier) {
+					return
+				}
+			case barriers <- i.barrier:
+			}
+		}
+		if !c.IsDone() {
+			close(out)
+		}
+	}()
+	return res, in
+}
+
+// queued returns the occupancy of the buffer ahead of the Context's Processor
+func (c *Context[_, _]) queued() metrics.Queue {
+	if c.queue != nil {
+		return c.queue
+	}
+	in := c.In
+	return func() (int, int) {
+		return len(in), cap(in)
+	}
+}
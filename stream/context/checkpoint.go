@@ -0,0 +1,387 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+type (
+	// Barrier flows through a Stream's Processors alongside its messages.
+	// Each Processor captures its state into the Barrier once it has handled
+	// all the messages that preceded it, so the state captured by the
+	// Barrier is consistent across the entire Stream
+	Barrier struct {
+		ID      uint64
+		state   map[string]any
+		tracked map[string]func() (any, error)
+		commits []func()
+		err     error
+		mu      sync.Mutex
+	}
+
+	// Codec encodes the state captured by a Barrier, so that it can be kept
+	// outside the process, and decodes it when the Processors are restored.
+	// Decode is given a pointer to a value of the type that was encoded
+	Codec interface {
+		Encode(any) ([]byte, error)
+		Decode([]byte, any) error
+	}
+
+	// state is a named function that captures part of a Processor's state
+	state struct {
+		name     string
+		snapshot func() (any, error)
+	}
+
+	// encoded wraps the state passed to a Codec, so that a state that's a nil
+	// pointer is encoded as an empty value
+	encoded[State any] struct {
+		State State
+	}
+)
+
+var (
+	ErrStateEncode = errors.New("could not encode state")
+	ErrStateDecode = errors.New("could not decode state")
+)
+
+// MakeBarrier instantiates a new Barrier with the provided ID
+func MakeBarrier(id uint64) *Barrier {
+	return &Barrier{
+		ID:    id,
+		state: map[string]any{},
+	}
+}
+
+// State returns the state captured by the Barrier, keyed by the path of each
+// Processor within the Stream and the name it gave to its state
+func (b *Barrier) State() map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return maps.Clone(b.state)
+}
+
+// OnCommit registers a function to be called once the Snapshot of the
+// Barrier's state has been saved. Processors with side effects, such as
+// node.TopicProducer, use it to hold those effects back until the messages
+// that caused them will no longer be replayed
+func (b *Barrier) OnCommit(fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.commits = append(b.commits, fn)
+}
+
+// Commit calls the functions registered with OnCommit, in the order they were
+// registered. It's called once the Barrier's state has been saved
+func (b *Barrier) Commit() {
+	b.mu.Lock()
+	commits := b.commits
+	b.commits = nil
+	b.mu.Unlock()
+	for _, fn := range commits {
+		fn()
+	}
+}
+
+// Capture records the current state of every Processor tracked by the
+// Barrier. It's meant for the final Barrier of a Stream, once all of its
+// Processors have returned
+func (b *Barrier) Capture() {
+	b.mu.Lock()
+	tracked := maps.Clone(b.tracked)
+	b.mu.Unlock()
+	for key, snapshot := range tracked {
+		b.record(key, snapshot)
+	}
+}
+
+// Err returns the first error encountered while capturing the Barrier's
+// state, if any. The state of a Barrier with an error is incomplete
+func (b *Barrier) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+func (b *Barrier) record(key string, snapshot func() (any, error)) {
+	value, err := snapshot()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil {
+		if b.err == nil {
+			b.err = fmt.Errorf("%w: %s: %w", ErrStateEncode, key, err)
+		}
+		return
+	}
+	b.state[key] = value
+}
+
+func (b *Barrier) track(key string, snapshot func() (any, error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tracked == nil {
+		b.tracked = map[string]func() (any, error){}
+	}
+	b.tracked[key] = snapshot
+}
+
+// WithBarriers returns a copy of the Context that receives Barriers from the
+// first channel, and passes them downstream to the second once they have been
+// checkpointed
+func WithBarriers[In, Out any](
+	c *Context[In, Out], in <-chan *Barrier, out chan<- *Barrier,
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.barrierIn = in
+	res.barrierOut = out
+	res.release = nil
+	return res
+}
+
+// WithFinal returns a copy of the Context whose Processors have their state
+// tracked by the provided Barrier, so that it can capture the state they're
+// left in once they've all returned
+func WithFinal[In, Out any](
+	c *Context[In, Out], final *Barrier,
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.final = final
+	return res
+}
+
+// WithCodec returns a copy of the Context whose Processors encode the state
+// they capture, and decode the state they restore, using the provided Codec
+func WithCodec[In, Out any](
+	c *Context[In, Out], codec Codec,
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.codec = codec
+	return res
+}
+
+// WithRestored returns a copy of the Context whose Processors restore their
+// state from the provided checkpointed state, as returned by Barrier.State
+func WithRestored[In, Out any](
+	c *Context[In, Out], restored map[string]any,
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.restored = restored
+	return res
+}
+
+// WithState returns a copy of the Context that captures the named state of
+// its Processor, using the provided function, each time it checkpoints a
+// Barrier. If the Context was restored from checkpointed state that includes
+// the named state, the restored value is returned as well. If the Context has
+// a Codec, the state is encoded when it's captured and decoded when it's
+// restored. A state that can't be decoded is reported as Fatal Advice
+func WithState[In, Out, State any](
+	c *Context[In, Out], name string, snapshot func() State,
+) (*Context[In, Out], State, bool) {
+	res := derive(c, c.Done, c.In, c.Out)
+	codec := c.codec
+	st := &state{
+		name: name,
+		snapshot: func() (any, error) {
+			if codec == nil {
+				return snapshot(), nil
+			}
+			return codec.Encode(&encoded[State]{State: snapshot()})
+		},
+	}
+	res.states = append(slices.Clone(c.states), st)
+	if c.final != nil {
+		c.final.track(c.key(name), st.snapshot)
+	}
+	restored, ok := restoreState[State](c, name)
+	return res, restored, ok
+}
+
+func restoreState[State, In, Out any](
+	c *Context[In, Out], name string,
+) (State, bool) {
+	var zero State
+	value, ok := c.restored[c.key(name)]
+	if !ok {
+		return zero, false
+	}
+	if c.codec == nil {
+		res, ok := value.(State)
+		return res, ok
+	}
+	var res encoded[State]
+	data, ok := value.([]byte)
+	if !ok {
+		c.Fatal(fmt.Errorf("%w: %s: not encoded", ErrStateDecode, c.key(name)))
+		return zero, false
+	}
+	if err := c.codec.Decode(data, &res); err != nil {
+		c.Fatal(fmt.Errorf("%w: %s: %w", ErrStateDecode, c.key(name), err))
+		return zero, false
+	}
+	return res.State, true
+}
+
+// Chain links the Barriers of two Contexts, so that the Barriers passed
+// downstream by the left Context are received by the right Context
+func Chain[LeftIn, LeftOut, RightIn, RightOut any](
+	left *Context[LeftIn, LeftOut], right *Context[RightIn, RightOut],
+) (*Context[LeftIn, LeftOut], *Context[RightIn, RightOut]) {
+	l := derive(left, left.Done, left.In, left.Out)
+	l.path = left.path + "/0"
+	l.name = ""
+	r := derive(right, right.Done, right.In, right.Out)
+	r.path = right.path + "/1"
+	r.name = ""
+	if left.barrierIn != nil {
+		ch := make(chan *Barrier)
+		l.barrierOut = ch
+		l.release = nil
+		r.barrierIn = ch
+	}
+	return l, r
+}
+
+// Fork returns n copies of the Context for Processors that run side by side,
+// sharing its output. Each Barrier received by the Context is delivered to
+// all the copies, and those that have checkpointed it wait for the others to
+// do the same. The aligned Barrier is then passed downstream by the Context
+func Fork[In, Out any](c *Context[In, Out], n int) []*Context[In, Out] {
+	return fork(c, n, c.barrierOut)
+}
+
+// ForkJoin is like Fork, except that the aligned Barrier is received by the
+// returned Context rather than being passed downstream. This is meant for
+// Processors that consume the output of the copies
+func ForkJoin[In, Out any](
+	c *Context[In, Out], n int,
+) ([]*Context[In, Out], *Context[In, Out]) {
+	joined := derive(c, c.Done, c.In, c.Out)
+	if c.barrierIn == nil {
+		return fork(c, n, nil), joined
+	}
+	aligned := make(chan *Barrier)
+	joined.barrierIn = aligned
+	return fork(c, n, aligned), joined
+}
+
+func fork[In, Out any](
+	c *Context[In, Out], n int, out chan<- *Barrier,
+) []*Context[In, Out] {
+	res := make([]*Context[In, Out], n)
+	for i := range res {
+		res[i] = derive(c, c.Done, c.In, c.Out)
+		res[i].path = c.path + "/" + strconv.Itoa(i)
+		res[i].name = ""
+	}
+	if c.barrierIn == nil {
+		return res
+	}
+
+	ins := make([]chan *Barrier, n)
+	gone := make([]chan Done, n)
+	arrived := make(chan *Barrier)
+	release := make(chan Done)
+	for i, f := range res {
+		ins[i] = make(chan *Barrier)
+		gone[i] = make(chan Done)
+		f.barrierIn = ins[i]
+		f.barrierOut = arrived
+		f.release = release
+		var once sync.Once
+		f.group = makeGroup(c.group, func() {
+			once.Do(func() { close(gone[i]) })
+		})
+	}
+
+	go func() {
+		for {
+			var b *Barrier
+			select {
+			case <-c.Done:
+				return
+			case b = <-c.barrierIn:
+			}
+			count := 0
+			for i, in := range ins {
+				select {
+				case <-c.Done:
+					return
+				case <-gone[i]:
+				case in <- b:
+					count++
+				}
+			}
+			for range count {
+				select {
+				case <-c.Done:
+					return
+				case <-arrived:
+				}
+			}
+			select {
+			case <-c.Done:
+				return
+			case out <- b:
+			}
+			for range count {
+				select {
+				case <-c.Done:
+					return
+				case release <- Done{}:
+				}
+			}
+		}
+	}()
+	return res
+}
+
+// Barriers returns the channel on which Barriers arrive. Processors that
+// receive from In directly, rather than calling FetchMessage, must also
+// receive from this channel, passing each Barrier to Checkpoint. While the
+// Stream is checkpointing, In is unbuffered, so a Barrier is only ready once
+// the messages sent ahead of it have been received
+func (c *Context[_, _]) Barriers() <-chan *Barrier {
+	return c.barrierIn
+}
+
+// Final returns the Barrier that captures the state of the Stream's Processors
+// once they've all returned, if the Stream is checkpointing. A Processor that
+// holds back side effects until they're committed registers those it still
+// holds with this Barrier when its input is closed
+func (c *Context[_, _]) Final() *Barrier {
+	return c.final
+}
+
+// Checkpoint captures the state registered with the Context into the Barrier
+// and then passes the Barrier downstream
+func (c *Context[_, _]) Checkpoint(b *Barrier) bool {
+	for _, s := range c.states {
+		b.record(c.key(s.name), s.snapshot)
+	}
+	if c.barrierOut == nil {
+		return !c.IsDone()
+	}
+	select {
+	case <-c.Done:
+		return false
+	case c.barrierOut <- b:
+	}
+	if c.release == nil {
+		return true
+	}
+	select {
+	case <-c.Done:
+		return false
+	case <-c.release:
+		return true
+	}
+}
+
+func (c *Context[_, _]) key(name string) string {
+	return c.path + "/" + name
+}
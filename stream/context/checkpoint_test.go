@@ -0,0 +1,199 @@
+package context_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+)
+
+func TestCheckpointChain(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	barrierIn := make(chan *context.Barrier)
+	barrierOut := make(chan *context.Barrier)
+
+	c := context.Make[any, any](done, make(chan context.Advice), nil, nil)
+	c = context.WithBarriers(c, barrierIn, barrierOut)
+	c = context.WithRestored(c, map[string]any{"/1/count": 5})
+	l, r := context.Chain(c, c)
+
+	l, _, ok := context.WithState(l, "count", func() int { return 1 })
+	as.False(ok)
+	r, restored, ok := context.WithState(r, "count", func() int { return 2 })
+	as.True(ok)
+	as.Equal(5, restored)
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		as.True(l.Checkpoint(<-l.Barriers()))
+	})
+	wg.Go(func() {
+		as.True(r.Checkpoint(<-r.Barriers()))
+	})
+
+	b := context.MakeBarrier(1)
+	barrierIn <- b
+	as.Equal(b, <-barrierOut)
+	wg.Wait()
+	as.Equal(map[string]any{
+		"/0/count": 1,
+		"/1/count": 2,
+	}, b.State())
+}
+
+func TestCheckpointFork(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	barrierIn := make(chan *context.Barrier)
+	barrierOut := make(chan *context.Barrier)
+
+	c := context.Make[any, any](done, make(chan context.Advice), nil, nil)
+	c = context.WithBarriers(c, barrierIn, barrierOut)
+
+	var wg sync.WaitGroup
+	for i, f := range context.Fork(c, 3) {
+		f, _, _ = context.WithState(f, "index", func() int { return i })
+		wg.Go(func() {
+			as.True(f.Checkpoint(<-f.Barriers()))
+		})
+	}
+
+	b := context.MakeBarrier(1)
+	barrierIn <- b
+	as.Equal(b, <-barrierOut)
+	wg.Wait()
+	as.Equal(map[string]any{
+		"/0/index": 0,
+		"/1/index": 1,
+		"/2/index": 2,
+	}, b.State())
+}
+
+func TestCheckpointWithoutBarriers(t *testing.T) {
+	as := assert.New(t)
+
+	c := context.Make[any, any](
+		make(chan context.Done), make(chan context.Advice), nil, nil,
+	)
+	as.Nil(c.Barriers())
+
+	l, r := context.Chain(c, c)
+	as.Nil(l.Barriers())
+	as.Nil(r.Barriers())
+	for _, f := range context.Fork(c, 2) {
+		as.Nil(f.Barriers())
+	}
+}
+
+func TestCheckpointQueued(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	barrierIn := make(chan *context.Barrier)
+
+	c := context.Make[any, any](done, make(chan context.Advice), nil, nil)
+	c = context.WithBuffer(context.WithBarriers(c, barrierIn, nil), 3)
+	qc, in := context.WithQueue[int](c)
+
+	b := context.MakeBarrier(1)
+	queued := make(chan struct{})
+	go func() {
+		in <- 1
+		in <- 2
+		barrierIn <- b
+		close(queued)
+		in <- 3
+		close(in)
+	}()
+
+	// the Barrier is queued between the messages sent before and after it
+	<-queued
+	msg, _, ok := qc.Receive()
+	as.True(ok)
+	as.Equal(1, msg)
+	msg, _, _ = qc.Receive()
+	as.Equal(2, msg)
+	_, res, ok := qc.Receive()
+	as.True(ok)
+	as.Equal(b, res)
+	msg, _, _ = qc.Receive()
+	as.Equal(3, msg)
+
+	_, _, ok = qc.Receive()
+	as.False(ok)
+}
+
+// failingCodec is a Codec that can't encode anything
+type failingCodec struct{}
+
+var errCodec = errors.New("codec failed")
+
+func (failingCodec) Encode(any) ([]byte, error) {
+	return nil, errCodec
+}
+
+func (failingCodec) Decode([]byte, any) error {
+	return errCodec
+}
+
+func TestCheckpointCodec(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+
+	codec := stream.GobCodec{}
+	data, err := codec.Encode(&struct{ State int }{State: 5})
+	as.Nil(err)
+
+	c := context.Make[any, any](done, make(chan context.Advice), nil, nil)
+	c = context.WithCodec(c, codec)
+	c = context.WithRestored(c, map[string]any{"/count": data})
+	c, restored, ok := context.WithState(c, "count", func() int { return 6 })
+	as.True(ok)
+	as.Equal(5, restored)
+	c, _, ok = context.WithState(c, "last", func() *int { return nil })
+	as.False(ok)
+
+	b := context.MakeBarrier(1)
+	as.True(c.Checkpoint(b))
+	as.Nil(b.Err())
+
+	var count struct{ State int }
+	as.Nil(codec.Decode(b.State()["/count"].([]byte), &count))
+	as.Equal(6, count.State)
+	var last struct{ State *int }
+	as.Nil(codec.Decode(b.State()["/last"].([]byte), &last))
+	as.Nil(last.State)
+}
+
+func TestCheckpointCodecErrors(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	monitor := make(chan context.Advice, 1)
+
+	c := context.Make[any, any](done, monitor, nil, nil)
+	c = context.WithCodec(c, failingCodec{})
+	c = context.WithRestored(c, map[string]any{"/count": []byte{}})
+	c, _, ok := context.WithState(c, "count", func() int { return 1 })
+	as.False(ok)
+	as.ErrorIs((<-monitor).(*context.Fatal), context.ErrStateDecode)
+
+	b := context.MakeBarrier(1)
+	as.True(c.Checkpoint(b))
+	as.ErrorIs(b.Err(), context.ErrStateEncode)
+	as.ErrorIs(b.Err(), errCodec)
+	as.Empty(b.State())
+}
@@ -2,7 +2,14 @@ package context
 
 import (
 	"fmt"
+	"log/slog"
 	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/kode4food/caravan/stream/metrics"
+	"github.com/kode4food/caravan/stream/topology"
+	"github.com/kode4food/caravan/stream/trace"
 )
 
 type (
@@ -15,6 +22,29 @@ type (
 		Monitor chan<- Advice
 		In      <-chan In
 		Out     chan<- Out
+		drain   <-chan Done
+		group   *group
+
+		supervisor Supervisor
+		abandoned  func()
+		barrierIn  <-chan *Barrier
+		barrierOut chan<- *Barrier
+		release    <-chan Done
+		final      *Barrier
+		codec      Codec
+		path       string
+		restored   map[string]any
+		states     []*state
+		topology   *topology.Builder
+		parent     string
+		name       string
+		recorder   *metrics.Recorder
+		probe      *metrics.Probe
+		tracer     trace.Tracer
+		tracing    *tracing
+		logger     *slog.Logger
+		queue      metrics.Queue
+		buffer     int
 	}
 
 	Done struct{}
@@ -25,41 +55,151 @@ type (
 	// This should only be used in exceptional cases.
 	Stop struct{}
 
+	// Debug is Advice that reports a diagnostic message to the Stream,
+	// along with the stack of the go routine that gave it
 	Debug struct {
+		Origin
 		Message string
 		Stack   []byte
 	}
 
 	// Error is Advice that reports a recoverable error to the Stream.
-	Error struct{ error }
+	Error struct {
+		error
+		Origin
+	}
 
 	// Fatal is Advice that reports a non-recoverable error to the Stream. The
 	// Stream will be stopped when encountering such an error.
-	Fatal struct{ error }
+	Fatal struct {
+		error
+		Origin
+	}
+
+	// Origin identifies the Processor that gave some Advice by its position
+	// within the Stream and the name it was given, if any
+	Origin struct {
+		Node string
+		Name string
+	}
 )
 
 func Make[In, Out any](
 	done <-chan Done, monitor chan<- Advice, in <-chan In, out chan<- Out,
 ) *Context[In, Out] {
-	return &Context[In, Out]{done, monitor, in, out}
+	return &Context[In, Out]{
+		Done:    done,
+		Monitor: monitor,
+		In:      in,
+		Out:     out,
+	}
 }
 
 func With[OldIn, OldOut, In, Out any](
 	c *Context[OldIn, OldOut], in chan In, out chan Out,
 ) *Context[In, Out] {
-	return Make(c.Done, c.Monitor, in, out)
+	return derive(c, c.Done, in, out)
 }
 
 func WithIn[OldIn, Out, In any](
 	c *Context[OldIn, Out], in chan In,
 ) *Context[In, Out] {
-	return Make(c.Done, c.Monitor, in, c.Out)
+	return derive(c, c.Done, in, c.Out)
 }
 
 func WithOut[In, OldOut, Out any](
 	c *Context[In, OldOut], out chan Out,
 ) *Context[In, Out] {
-	return Make(c.Done, c.Monitor, c.In, out)
+	return derive(c, c.Done, c.In, out)
+}
+
+// WithCancel returns a copy of the Context whose Done channel is closed when
+// the returned function is called, or when the parent's Done channel is
+// closed, whichever happens first
+func WithCancel[In, Out any](c *Context[In, Out]) (*Context[In, Out], func()) {
+	done := make(chan Done)
+	cancel := make(chan Done)
+	go func() {
+		defer close(done)
+		select {
+		case <-c.Done:
+		case <-cancel:
+		}
+	}()
+	var once sync.Once
+	return derive(c, done, c.In, c.Out), func() {
+		once.Do(func() { close(cancel) })
+	}
+}
+
+// WithCompletion returns a copy of the Context that tracks the Processors
+// started with it as a separate group. The provided function is called once
+// all of those Processors have returned. The group remains part of the
+// Context's existing group
+func WithCompletion[In, Out any](
+	c *Context[In, Out], fn func(),
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.group = makeGroup(c.group, fn)
+	return res
+}
+
+// WithDrain returns a copy of the Context whose Draining channel is the one
+// provided
+func WithDrain[In, Out any](
+	c *Context[In, Out], drain <-chan Done,
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.drain = drain
+	return res
+}
+
+func derive[OldIn, OldOut, In, Out any](
+	c *Context[OldIn, OldOut], done <-chan Done, in <-chan In, out chan<- Out,
+) *Context[In, Out] {
+	res := Make(done, c.Monitor, in, out)
+	res.drain = c.drain
+	res.group = c.group
+	res.supervisor = c.supervisor
+	res.abandoned = c.abandoned
+	res.barrierIn = c.barrierIn
+	res.barrierOut = c.barrierOut
+	res.release = c.release
+	res.final = c.final
+	res.codec = c.codec
+	res.path = c.path
+	res.restored = c.restored
+	res.topology = c.topology
+	res.parent = c.parent
+	res.name = c.name
+	res.recorder = c.recorder
+	res.probe = c.probe
+	res.tracer = c.tracer
+	res.tracing = c.tracing
+	res.logger = c.logger
+	res.buffer = c.buffer
+	// the queue is only that of the copy if it receives from the same In
+	if any(in) == any(c.In) {
+		res.queue = c.queue
+	}
+	return res
+}
+
+// Go runs the provided function in a new go routine, tracking it as part of
+// the Context's group of running Processors
+func (c *Context[_, _]) Go(fn func()) {
+	c.group.enter()
+	go func() {
+		defer c.group.exit()
+		fn()
+	}()
+}
+
+// Draining returns a channel that is closed when the Stream has been asked to
+// drain. Sources that block on external input should stop pulling new
+// messages once it's closed
+func (c *Context[_, _]) Draining() <-chan Done {
+	return c.drain
 }
 
 func (c *Context[In, Out]) IsDone() bool {
@@ -71,21 +211,66 @@ func (c *Context[In, Out]) IsDone() bool {
 	}
 }
 
+// FetchMessage receives the next message from In. Any Barriers that arrive in
+// the meantime are checkpointed
 func (c *Context[In, Out]) FetchMessage() (In, bool) {
+	var zero In
+	for {
+		msg, b, ok := c.Receive()
+		switch {
+		case !ok:
+			return zero, false
+		case b == nil:
+			return msg, true
+		case !c.Checkpoint(b):
+			return zero, false
+		}
+	}
+}
+
+// Receive returns the next message or Barrier to arrive at the Context, in the
+// order they were sent. The result is false once In is closed or the Context
+// is done
+func (c *Context[In, Out]) Receive() (In, *Barrier, bool) {
+	var zero In
 	select {
 	case <-c.Done:
-		var zero In
-		return zero, false
-	case msg := <-c.In:
-		return msg, true
+		return zero, nil, false
+	case b := <-c.barrierIn:
+		return zero, b, true
+	case msg, ok := <-c.In:
+		if !ok {
+			return zero, nil, false
+		}
+		c.Received(msg)
+		return msg, nil, true
 	}
 }
 
+// ForwardResult sends a result to Out. If the Context is recording metrics,
+// the time spent blocked waiting for the downstream to accept it is included.
+// If it's tracing, the result carries the SpanContext of the message being
+// handled
 func (c *Context[In, Out]) ForwardResult(res Out) bool {
+	if c.tracing != nil {
+		var end func()
+		res, end = inject(c.tracing, res)
+		defer end()
+	}
+	if c.probe == nil {
+		select {
+		case <-c.Done:
+			return false
+		case c.Out <- res:
+			return true
+		}
+	}
+	start := time.Now()
 	select {
 	case <-c.Done:
 		return false
 	case c.Out <- res:
+		c.probe.Forwarded(start)
 		return true
 	}
 }
@@ -101,6 +286,7 @@ func (c *Context[In, Out]) Advise(a Advice) bool {
 
 func (c *Context[_, _]) Debugf(format string, v ...any) bool {
 	return c.Advise(&Debug{
+		Origin:  c.Origin(),
 		Message: fmt.Sprintf(format, v...),
 		Stack:   debug.Stack(),
 	})
@@ -111,7 +297,11 @@ func (c *Context[_, _]) Errorf(format string, v ...any) bool {
 }
 
 func (c *Context[_, _]) Error(err error) bool {
-	return c.Advise(&Error{err})
+	c.failed(err)
+	return c.Advise(&Error{
+		error:  err,
+		Origin: c.Origin(),
+	})
 }
 
 func (c *Context[_, _]) Fatalf(format string, v ...any) bool {
@@ -119,7 +309,38 @@ func (c *Context[_, _]) Fatalf(format string, v ...any) bool {
 }
 
 func (c *Context[_, _]) Fatal(err error) bool {
-	return c.Advise(&Fatal{err})
+	c.failed(err)
+	return c.Advise(&Fatal{
+		error:  err,
+		Origin: c.Origin(),
+	})
+}
+
+// Origin identifies the Context's Processor as the origin of its Advice
+func (c *Context[_, _]) Origin() Origin {
+	return Origin{
+		Node: c.id(),
+		Name: c.name,
+	}
+}
+
+// LogAttrs returns the attributes that identify the Processor in a log record
+func (o Origin) LogAttrs() []slog.Attr {
+	res := []slog.Attr{slog.String("node", o.Node)}
+	if o.Name != "" {
+		res = append(res, slog.String("node_name", o.Name))
+	}
+	return res
+}
+
+// Unwrap returns the error being reported by the Error Advice
+func (e *Error) Unwrap() error {
+	return e.error
+}
+
+// Unwrap returns the error being reported by the Fatal Advice
+func (e *Fatal) Unwrap() error {
+	return e.error
 }
 
 func (Stop) advice()   {}
@@ -1,6 +1,7 @@
 package context_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -123,3 +124,109 @@ func TestMonitorMessages(t *testing.T) {
 
 	<-done
 }
+
+func TestContextClosedInput(t *testing.T) {
+	as := assert.New(t)
+
+	in := make(chan any)
+	close(in)
+	c := context.Make[any, any](make(chan context.Done), nil, in, nil)
+
+	msg, ok := c.FetchMessage()
+	as.Nil(msg)
+	as.False(ok)
+}
+
+func TestContextCancel(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	c1 := context.Make[any, any](done, nil, nil, nil)
+	c2, cancel := context.WithCancel(c1)
+	as.False(c2.IsDone())
+
+	cancel()
+	cancel()
+	<-c2.Done
+	as.False(c1.IsDone())
+
+	c3, _ := context.WithCancel(c1)
+	close(done)
+	<-c3.Done
+}
+
+func TestContextCompletion(t *testing.T) {
+	as := assert.New(t)
+
+	var order []string
+	finished := make(chan context.Done)
+	c1 := context.WithCompletion(
+		context.Make[any, any](nil, nil, nil, nil), func() {
+			order = append(order, "outer")
+			close(finished)
+		},
+	)
+	c2 := context.WithCompletion(c1, func() {
+		order = append(order, "inner")
+	})
+
+	release := make(chan context.Done)
+	c1.Go(func() {
+		c2.Go(func() { <-release })
+	})
+	close(release)
+	<-finished
+	as.Equal([]string{"inner", "outer"}, order)
+}
+
+func TestContextDraining(t *testing.T) {
+	as := assert.New(t)
+
+	c1 := context.Make[any, any](nil, nil, nil, nil)
+	as.Nil(c1.Draining())
+
+	drain := make(chan context.Done)
+	c2 := context.WithIn(context.WithDrain(c1, drain), make(chan any))
+	close(drain)
+	<-c2.Draining()
+}
+
+func TestContextRecover(t *testing.T) {
+	as := assert.New(t)
+
+	monitor := make(chan context.Advice, 1)
+	c := context.Make[any, any](make(chan context.Done), monitor, nil, nil)
+
+	as.False(c.Recover("boom", []byte("stack")))
+	f, ok := (<-monitor).(*context.Fatal)
+	as.True(ok)
+	as.EqualError(f, "processor panicked: boom")
+
+	var p *context.PanicError
+	as.ErrorAs(f, &p)
+	as.Equal("boom", p.Value)
+	as.Equal([]byte("stack"), p.Stack)
+
+	decision := context.Restart
+	abandoned := 0
+	s := context.WithSupervisor(c, func(err error) context.Decision {
+		as.ErrorAs(err, &p)
+		return decision
+	}, func() {
+		// the Advice has already been reported
+		as.Len(monitor, 1)
+		abandoned++
+	})
+	as.True(s.Recover("boom", nil))
+	_, ok = (<-monitor).(*context.Error)
+	as.True(ok)
+	as.Equal(0, abandoned)
+
+	decision = context.Abandon
+	errBoom := errors.New("boom")
+	as.False(s.Recover(errBoom, nil))
+	as.Equal(1, abandoned)
+	e, ok := (<-monitor).(*context.Error)
+	as.True(ok)
+	as.ErrorIs(e, errBoom)
+}
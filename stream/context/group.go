@@ -0,0 +1,48 @@
+package context
+
+import "sync"
+
+// group tracks a set of running Processors, calling its done function once
+// all of them have returned. A group is considered running within its parent
+// for as long as any of its own Processors are running
+type group struct {
+	parent *group
+	done   func()
+	count  int
+	mu     sync.Mutex
+}
+
+func makeGroup(parent *group, done func()) *group {
+	return &group{
+		parent: parent,
+		done:   done,
+	}
+}
+
+func (g *group) enter() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.count == 0 {
+		g.parent.enter()
+	}
+	g.count++
+}
+
+func (g *group) exit() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	g.count--
+	finished := g.count == 0
+	g.mu.Unlock()
+	if finished {
+		if g.done != nil {
+			g.done()
+		}
+		g.parent.exit()
+	}
+}
@@ -0,0 +1,22 @@
+package context
+
+import "log/slog"
+
+// WithLogger returns a copy of the Context whose Processors log to the
+// provided Logger
+func WithLogger[In, Out any](
+	c *Context[In, Out], l *slog.Logger,
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.logger = l
+	return res
+}
+
+// Logger returns the Logger that the Context's Processor should log to. If
+// none was provided, the default Logger is returned
+func (c *Context[_, _]) Logger() *slog.Logger {
+	if c.logger == nil {
+		return slog.Default()
+	}
+	return c.logger
+}
@@ -0,0 +1,55 @@
+package context
+
+import "github.com/kode4food/caravan/stream/metrics"
+
+// WithMetrics returns a copy of the Context whose Processors record their
+// metrics with the provided Recorder
+func WithMetrics[In, Out any](
+	c *Context[In, Out], r *metrics.Recorder,
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.recorder = r
+	return res
+}
+
+// Measure returns a copy of the Context that records the metrics of a
+// Processor of the specified kind at the Context's position in the Stream. It
+// has no effect unless the Context has a Recorder
+func (c *Context[In, Out]) Measure(kind string) *Context[In, Out] {
+	if c.recorder == nil {
+		return c
+	}
+	res := derive(c, c.Done, c.In, c.Out)
+	res.probe = c.recorder.Probe(c.id(), c.name, kind)
+	res.probe.Watch(c.queued())
+	return res
+}
+
+// WatchQueue has the metrics of the Context's Processor report the occupancy
+// of a buffer it holds messages in, rather than that of its input channel
+func (c *Context[_, _]) WatchQueue(q metrics.Queue) {
+	if c.probe != nil {
+		c.probe.Watch(q)
+	}
+}
+
+// Received records that the Context's Processor received a message, for the
+// sake of its metrics and tracing. FetchMessage does this, so only Processors
+// that receive from In directly need to call it
+func (c *Context[In, _]) Received(msg In) {
+	if c.probe != nil {
+		c.probe.Received()
+	}
+	if c.tracing != nil {
+		c.tracing.received(msg)
+	}
+}
+
+func (c *Context[_, _]) failed(err error) {
+	if c.probe != nil {
+		c.probe.Failed()
+	}
+	if c.tracing != nil {
+		c.tracing.failed(err)
+	}
+}
@@ -0,0 +1,87 @@
+package context
+
+import "fmt"
+
+type (
+	// Supervisor decides how a panicking Processor is handled. It's called
+	// from the Processor's own go routine before any Advice is reported
+	Supervisor func(err error) Decision
+
+	// Decision is returned by a Supervisor to indicate how a panicking
+	// Processor should be handled
+	Decision int
+
+	// PanicError is the error reported when a Processor panics. It includes
+	// the recovered value and the stack at the point of the panic
+	PanicError struct {
+		Value any
+		Stack []byte
+	}
+)
+
+// Supported Supervisor Decisions
+const (
+	// Escalate reports the panic as Fatal Advice, stopping the Stream
+	Escalate Decision = iota
+
+	// Restart reports the panic as Error Advice and restarts the Processor
+	// in place, using the same Context
+	Restart
+
+	// Abandon reports the panic as Error Advice and lets the Processor
+	// return. Once the Advice has been reported, the function provided to
+	// WithSupervisor is called so that the Stream can be restarted as a whole
+	Abandon
+)
+
+// WithSupervisor returns a copy of the Context whose panicking Processors are
+// handled by the provided Supervisor. If the Supervisor decides to Abandon a
+// Processor, the abandoned function is called after the panic is reported
+func WithSupervisor[In, Out any](
+	c *Context[In, Out], s Supervisor, abandoned func(),
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.supervisor = s
+	res.abandoned = abandoned
+	return res
+}
+
+// Recover reports a value recovered from a panicking Processor, along with
+// the stack at the point of the panic. Returns whether the Processor should be
+// restarted. Without a Supervisor, the panic is reported as Fatal Advice
+func (c *Context[_, _]) Recover(v any, stack []byte) bool {
+	err := &PanicError{
+		Value: v,
+		Stack: stack,
+	}
+	d := Escalate
+	if c.supervisor != nil {
+		d = c.supervisor(err)
+	}
+	switch d {
+	case Restart:
+		c.Error(err)
+		return true
+	case Abandon:
+		c.Error(err)
+		if c.abandoned != nil {
+			c.abandoned()
+		}
+		return false
+	default:
+		c.Fatal(err)
+		return false
+	}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("processor panicked: %v", e.Value)
+}
+
+// Unwrap returns the recovered value if it's an error
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
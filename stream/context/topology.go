@@ -0,0 +1,79 @@
+package context
+
+import (
+	"reflect"
+
+	"github.com/kode4food/caravan/stream/topology"
+)
+
+// WithTopology returns a copy of the Context that describes the Processors
+// started with it to the provided Builder, rather than running them. The
+// Context's Done channel is expected to have been closed already, so that any
+// Processor that does run returns immediately
+func WithTopology[In, Out any](
+	c *Context[In, Out], b *topology.Builder,
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.topology = b
+	return res
+}
+
+// WithName returns a copy of the Context whose Processor is identified by the
+// provided name
+func WithName[In, Out any](
+	c *Context[In, Out], name string,
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.name = name
+	return res
+}
+
+// Name returns the name given to the Context's Processor, if any
+func (c *Context[_, _]) Name() string {
+	return c.name
+}
+
+// Describing returns whether the Context is describing a Stream's topology
+// rather than running its Processors
+func (c *Context[_, _]) Describing() bool {
+	return c.topology != nil
+}
+
+// Describe records a Processor of the specified kind at the Context's
+// position in the topology being described. The returned Context attributes
+// the Processors started with it to that Processor
+func (c *Context[In, Out]) Describe(kind string) *Context[In, Out] {
+	if c.topology == nil {
+		return c
+	}
+	id := c.id()
+	c.topology.Add(c.parent, &topology.Node{
+		ID:   id,
+		Name: c.name,
+		Kind: kind,
+		In:   reflect.TypeFor[In]().String(),
+		Out:  reflect.TypeFor[Out]().String(),
+	})
+	res := derive(c, c.Done, c.In, c.Out)
+	res.parent = id
+	return res
+}
+
+// Connect declares how the Processors started with the Context by a composite
+// Processor are connected to one another. Composite Processors that only wire
+// their children together are left out of the Stream's metrics
+func (c *Context[_, _]) Connect(s topology.Shape) {
+	if c.topology != nil {
+		c.topology.Connect(c.id(), s)
+	}
+	if c.probe != nil && (s == topology.Sequence || s == topology.Parallel) {
+		c.probe.Wiring()
+	}
+}
+
+func (c *Context[_, _]) id() string {
+	if c.path == "" {
+		return "/"
+	}
+	return c.path
+}
@@ -0,0 +1,105 @@
+package context
+
+import (
+	"sync"
+
+	"github.com/kode4food/caravan/stream/trace"
+)
+
+// tracing holds the Span of the message being handled by a Processor
+type tracing struct {
+	tracer trace.Tracer
+	node   trace.Node
+	source bool
+	span   trace.Span
+	err    error
+	mu     sync.Mutex
+}
+
+// WithTracer returns a copy of the Context whose Processors start a Span with
+// the provided Tracer for each message they handle
+func WithTracer[In, Out any](
+	c *Context[In, Out], t trace.Tracer,
+) *Context[In, Out] {
+	res := derive(c, c.Done, c.In, c.Out)
+	res.tracer = t
+	return res
+}
+
+// Trace returns a copy of the Context that traces the messages handled by a
+// Processor of the specified kind at the Context's position in the Stream. If
+// the Processor is a source, whose input carries no data, it starts a Span
+// for each result it forwards instead. It has no effect unless the Context
+// has a Tracer
+func (c *Context[In, Out]) Trace(kind string, source bool) *Context[In, Out] {
+	if c.tracer == nil {
+		return c
+	}
+	res := derive(c, c.Done, c.In, c.Out)
+	res.tracing = &tracing{
+		tracer: c.tracer,
+		node: trace.Node{
+			ID:   c.id(),
+			Name: c.name,
+			Kind: kind,
+		},
+		source: source,
+	}
+	return res
+}
+
+// EndSpan ends the Span of the message being handled by the Context's
+// Processor, if any. Start calls it once the Processor returns
+func (c *Context[_, _]) EndSpan() {
+	if c.tracing != nil {
+		c.tracing.mu.Lock()
+		defer c.tracing.mu.Unlock()
+		c.tracing.end()
+	}
+}
+
+// received ends the Span of the previous message and starts one for the
+// provided message, as a child of the SpanContext it carries
+func (t *tracing) received(msg any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.end()
+	if !t.source {
+		t.span = t.tracer.Start(trace.SpanContextOf(msg), t.node)
+	}
+}
+
+func (t *tracing) failed(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.span != nil {
+		t.err = err
+	}
+}
+
+func (t *tracing) end() {
+	if t.span != nil {
+		t.span.End(t.err)
+		t.span = nil
+		t.err = nil
+	}
+}
+
+// inject has the result carry the SpanContext of the message being handled.
+// If there's no such message, a Span is started for the result itself, and
+// the returned function ends it
+func inject[Out any](t *tracing, res Out) (Out, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span, end := t.span, func() {}
+	if span == nil {
+		span = t.tracer.Start(trace.SpanContextOf(res), t.node)
+		end = func() { span.End(nil) }
+	}
+	if cr, ok := any(res).(trace.Carrier); ok {
+		if out, ok := cr.WithSpanContext(span.Context()).(Out); ok {
+			res = out
+		}
+	}
+	return res, end
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// ExpvarSink is a Sink that publishes the metrics of a Stream's Processors
+// as an expvar.Map. Each Processor is given a Map of its own, keyed by its ID
+type ExpvarSink struct {
+	vars *expvar.Map
+	mu   sync.Mutex
+}
+
+// MakeExpvarSink instantiates a new ExpvarSink that publishes its metrics
+// under the provided name. If a Map has already been published under that
+// name, such as by an earlier run of the same Stream, it is reused
+func MakeExpvarSink(name string) *ExpvarSink {
+	vars, ok := expvar.Get(name).(*expvar.Map)
+	if !ok {
+		vars = expvar.NewMap(name)
+	}
+	return &ExpvarSink{
+		vars: vars,
+	}
+}
+
+// Received increments the Processor's received count
+func (s *ExpvarSink) Received(node string) {
+	s.node(node).Add("received", 1)
+}
+
+// Forwarded increments the Processor's forwarded count, and adds to its
+// total latency and blocked time, both in nanoseconds
+func (s *ExpvarSink) Forwarded(node string, latency, blocked time.Duration) {
+	m := s.node(node)
+	m.Add("forwarded", 1)
+	m.Add("latency_ns", int64(latency))
+	m.Add("blocked_ns", int64(blocked))
+}
+
+// Failed increments the Processor's error count
+func (s *ExpvarSink) Failed(node string) {
+	s.node(node).Add("errors", 1)
+}
+
+func (s *ExpvarSink) node(id string) *expvar.Map {
+	if m, ok := s.vars.Get(id).(*expvar.Map); ok {
+		return m
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m, ok := s.vars.Get(id).(*expvar.Map); ok {
+		return m
+	}
+	m := new(expvar.Map)
+	s.vars.Set(id, m)
+	return m
+}
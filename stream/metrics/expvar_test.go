@@ -0,0 +1,34 @@
+package metrics_test
+
+import (
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream/metrics"
+)
+
+func TestExpvarSink(t *testing.T) {
+	as := assert.New(t)
+
+	// expvar names are global, so each run publishes under its own
+	name := fmt.Sprintf("caravan_test_%d", time.Now().UnixNano())
+	s := metrics.MakeExpvarSink(name)
+	s.Received("/0")
+	s.Forwarded("/0", time.Millisecond, time.Microsecond)
+	s.Failed("/0")
+
+	again := metrics.MakeExpvarSink(name)
+	again.Received("/0")
+
+	vars := expvar.Get(name).(*expvar.Map)
+	n := vars.Get("/0").(*expvar.Map)
+	as.Equal("2", n.Get("received").String())
+	as.Equal("1", n.Get("forwarded").String())
+	as.Equal("1", n.Get("errors").String())
+	as.Equal("1000000", n.Get("latency_ns").String())
+	as.Equal("1000", n.Get("blocked_ns").String())
+}
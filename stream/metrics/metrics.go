@@ -0,0 +1,247 @@
+package metrics
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// Sink receives the measurements taken as messages pass through the
+	// Processors of a Stream. Each Processor is identified by its ID within
+	// the Stream's topology. A Sink is called from the Processors' own go
+	// routines, so it must be safe for concurrent use
+	Sink interface {
+		// Received is called when a Processor receives a message
+		Received(node string)
+
+		// Forwarded is called when a Processor forwards a message, with the
+		// time spent processing it since the last message was received and
+		// the time spent blocked waiting for the downstream to accept it
+		Forwarded(node string, latency, blocked time.Duration)
+
+		// Failed is called when a Processor reports an error
+		Failed(node string)
+	}
+
+	// Snapshot is the state of a Stream's metrics at a point in time
+	Snapshot struct {
+		Nodes []*Node `json:"nodes"`
+	}
+
+	// Node holds the metrics of a single Processor
+	Node struct {
+		ID        string        `json:"id"`
+		Name      string        `json:"name,omitempty"`
+		Kind      string        `json:"kind"`
+		Received  uint64        `json:"received"`
+		Forwarded uint64        `json:"forwarded"`
+		Errors    uint64        `json:"errors"`
+		Latency   *Histogram    `json:"latency"`
+		Blocked   time.Duration `json:"blocked"`
+		Queued    int           `json:"queued"`
+		Capacity  int           `json:"capacity"`
+	}
+
+	// Histogram counts durations into buckets. Each of the Counts is the
+	// number of durations no greater than the corresponding Bound, and
+	// greater than the one before it. The final count is of the durations
+	// that exceed every Bound
+	Histogram struct {
+		Bounds []time.Duration `json:"bounds"`
+		Counts []uint64        `json:"counts"`
+		Count  uint64          `json:"count"`
+		Sum    time.Duration   `json:"sum"`
+	}
+
+	// Recorder accumulates the metrics of a Stream's Processors, passing
+	// each measurement on to a Sink if one is provided
+	Recorder struct {
+		sink   Sink
+		probes map[string]*Probe
+		order  []string
+		mu     sync.Mutex
+	}
+
+	// Probe takes the measurements of a single Processor
+	Probe struct {
+		id        string
+		name      string
+		kind      string
+		sink      Sink
+		wiring    atomic.Bool
+		received  atomic.Uint64
+		forwarded atomic.Uint64
+		errors    atomic.Uint64
+		blocked   atomic.Int64
+		latest    atomic.Int64
+		latency   histogram
+		queue     atomic.Pointer[Queue]
+	}
+
+	// Queue reports the number of messages waiting in the buffer ahead of a
+	// Processor, and the buffer's capacity
+	Queue func() (queued int, capacity int)
+
+	histogram struct {
+		counts [len(Bounds) + 1]atomic.Uint64
+		count  atomic.Uint64
+		sum    atomic.Int64
+	}
+)
+
+// Bounds are the upper bounds of the buckets used by latency Histograms
+var Bounds = [...]time.Duration{
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// MakeRecorder instantiates a new Recorder. The Sink may be nil
+func MakeRecorder(sink Sink) *Recorder {
+	return &Recorder{
+		sink:   sink,
+		probes: map[string]*Probe{},
+	}
+}
+
+// Probe returns the Probe for the Processor with the provided ID, creating
+// it if necessary. A Processor that's restarted keeps its Probe, so that its
+// metrics accumulate across restarts. If the Processor is wrapped by another,
+// such as by node.Named, the kind of the innermost Processor is kept
+func (r *Recorder) Probe(id, name, kind string) *Probe {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p, ok := r.probes[id]; ok {
+		p.kind = kind
+		if name != "" {
+			p.name = name
+		}
+		return p
+	}
+	p := &Probe{
+		id:   id,
+		name: name,
+		kind: kind,
+		sink: r.sink,
+	}
+	r.probes[id] = p
+	r.order = append(r.order, id)
+	return p
+}
+
+// Snapshot returns the current metrics of the Processors that have been
+// probed, in the order they were started. Processors that only wire others
+// together, such as Bind, are left out
+func (r *Recorder) Snapshot() *Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := &Snapshot{
+		Nodes: []*Node{},
+	}
+	for _, id := range r.order {
+		if p := r.probes[id]; !p.wiring.Load() {
+			res.Nodes = append(res.Nodes, p.snapshot())
+		}
+	}
+	return res
+}
+
+// Node returns the metrics of the Processor with the provided ID
+func (s *Snapshot) Node(id string) (*Node, bool) {
+	for _, n := range s.Nodes {
+		if n.ID == id {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// Wiring marks the Processor as one that only wires others together
+func (p *Probe) Wiring() {
+	p.wiring.Store(true)
+}
+
+// Watch has the Probe report the occupancy of the provided Queue, replacing
+// any Queue it was watching before
+func (p *Probe) Watch(q Queue) {
+	p.queue.Store(&q)
+}
+
+// Received records that the Processor received a message
+func (p *Probe) Received() {
+	p.received.Add(1)
+	p.latest.Store(time.Now().UnixNano())
+	if p.sink != nil {
+		p.sink.Received(p.id)
+	}
+}
+
+// Forwarded records that the Processor forwarded a message, having begun to
+// do so at the provided time
+func (p *Probe) Forwarded(start time.Time) {
+	blocked := time.Since(start)
+	var latency time.Duration
+	if latest := p.latest.Load(); latest != 0 {
+		latency = max(0, time.Duration(start.UnixNano()-latest))
+	}
+	p.forwarded.Add(1)
+	p.blocked.Add(int64(blocked))
+	p.latency.observe(latency)
+	if p.sink != nil {
+		p.sink.Forwarded(p.id, latency, blocked)
+	}
+}
+
+// Failed records that the Processor reported an error
+func (p *Probe) Failed() {
+	p.errors.Add(1)
+	if p.sink != nil {
+		p.sink.Failed(p.id)
+	}
+}
+
+func (p *Probe) snapshot() *Node {
+	var queued, capacity int
+	if q := p.queue.Load(); q != nil {
+		queued, capacity = (*q)()
+	}
+	return &Node{
+		ID:        p.id,
+		Name:      p.name,
+		Kind:      p.kind,
+		Received:  p.received.Load(),
+		Forwarded: p.forwarded.Load(),
+		Errors:    p.errors.Load(),
+		Latency:   p.latency.snapshot(),
+		Blocked:   time.Duration(p.blocked.Load()),
+		Queued:    queued,
+		Capacity:  capacity,
+	}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	i := 0
+	for i < len(Bounds) && d > Bounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+}
+
+func (h *histogram) snapshot() *Histogram {
+	res := &Histogram{
+		Bounds: Bounds[:],
+		Counts: make([]uint64, len(h.counts)),
+		Count:  h.count.Load(),
+		Sum:    time.Duration(h.sum.Load()),
+	}
+	for i := range h.counts {
+		res.Counts[i] = h.counts[i].Load()
+	}
+	return res
+}
@@ -0,0 +1,82 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream/metrics"
+)
+
+type countingSink struct {
+	received  int
+	forwarded int
+	failed    int
+}
+
+func (s *countingSink) Received(string) {
+	s.received++
+}
+
+func (s *countingSink) Forwarded(string, time.Duration, time.Duration) {
+	s.forwarded++
+}
+
+func (s *countingSink) Failed(string) {
+	s.failed++
+}
+
+func TestRecorder(t *testing.T) {
+	as := assert.New(t)
+
+	sink := &countingSink{}
+	r := metrics.MakeRecorder(sink)
+	p := r.Probe("/0", "", "Map")
+	p.Received()
+	p.Forwarded(time.Now())
+	p.Received()
+	p.Failed()
+
+	w := r.Probe("/", "", "Bind")
+	w.Wiring()
+	as.Same(p, r.Probe("/0", "double", "Map"))
+
+	snap := r.Snapshot()
+	as.Len(snap.Nodes, 1)
+	n, ok := snap.Node("/0")
+	as.True(ok)
+	as.Equal("double", n.Name)
+	as.Equal("Map", n.Kind)
+	as.Equal(uint64(2), n.Received)
+	as.Equal(uint64(1), n.Forwarded)
+	as.Equal(uint64(1), n.Errors)
+	as.Equal(uint64(1), n.Latency.Count)
+	as.Len(n.Latency.Counts, len(metrics.Bounds)+1)
+
+	_, ok = snap.Node("/")
+	as.False(ok)
+
+	as.Equal(2, sink.received)
+	as.Equal(1, sink.forwarded)
+	as.Equal(1, sink.failed)
+}
+
+func TestHistogram(t *testing.T) {
+	as := assert.New(t)
+
+	r := metrics.MakeRecorder(nil)
+	p := r.Probe("/", "", "Map")
+	p.Received()
+	time.Sleep(2 * time.Millisecond)
+	p.Forwarded(time.Now())
+	p.Forwarded(time.Now().Add(-2 * time.Second))
+
+	n, _ := r.Snapshot().Node("/")
+	h := n.Latency
+	as.Equal(uint64(2), h.Count)
+	as.Equal(uint64(1), h.Counts[3])
+	as.Equal(uint64(1), h.Counts[0])
+	as.Greater(h.Sum, 2*time.Millisecond)
+	as.Greater(n.Blocked, 2*time.Second)
+}
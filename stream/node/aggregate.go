@@ -8,13 +8,15 @@ import (
 // Reduce constructs a processor that reduces the messages it sees into some
 // form of aggregated messages, based on the provided function
 func Reduce[In, Out any](r Reducer[Out, In]) stream.Processor[In, Out] {
-	return reduce(r, func(c *context.Context[In, Out]) (Out, bool) {
-		var zero Out
-		if msg, ok := c.FetchMessage(); ok {
-			return r(zero, msg), true
-		}
-		return zero, false
-	})
+	return func(c *context.Context[In, Out]) {
+		reduce(c, r, func(c *context.Context[In, Out]) (Out, bool) {
+			var zero Out
+			if msg, ok := c.FetchMessage(); ok {
+				return r(zero, msg), true
+			}
+			return zero, false
+		})
+	}
 }
 
 // ReduceFrom constructs a processor that reduces the messages it sees into
@@ -23,19 +25,23 @@ func Reduce[In, Out any](r Reducer[Out, In]) stream.Processor[In, Out] {
 func ReduceFrom[In, Out any](
 	r Reducer[Out, In], init Out,
 ) stream.Processor[In, Out] {
-	return reduce(r, func(_ *context.Context[In, Out]) (Out, bool) {
-		return init, true
-	})
+	return func(c *context.Context[In, Out]) {
+		reduce(c, r, func(*context.Context[In, Out]) (Out, bool) {
+			return init, true
+		})
+	}
 }
 
 // Scan constructs a Processor that applies a reducer function to each message
 // and emits all intermediate results. Unlike Reduce which only emits the final
 // result, Scan emits after each message
 func Scan[In, Out any](r Reducer[Out, In]) stream.Processor[In, Out] {
-	var zero Out
-	return reduce(r, func(_ *context.Context[In, Out]) (Out, bool) {
-		return zero, true
-	})
+	return func(c *context.Context[In, Out]) {
+		reduce(c, r, func(*context.Context[In, Out]) (Out, bool) {
+			var zero Out
+			return zero, true
+		})
+	}
 }
 
 // ScanFrom constructs a Processor that applies a reducer function to each
@@ -43,30 +49,37 @@ func Scan[In, Out any](r Reducer[Out, In]) stream.Processor[In, Out] {
 func ScanFrom[In, Out any](
 	r Reducer[Out, In], init Out,
 ) stream.Processor[In, Out] {
-	return reduce(r, func(_ *context.Context[In, Out]) (Out, bool) {
-		return init, true
-	})
+	return func(c *context.Context[In, Out]) {
+		reduce(c, r, func(*context.Context[In, Out]) (Out, bool) {
+			return init, true
+		})
+	}
 }
 
 // reduce provides the common reduction loop for both reduce and scan
 func reduce[In, Out any](
-	fn Reducer[Out, In], init func(*context.Context[In, Out]) (Out, bool),
-) stream.Processor[In, Out] {
-	return func(c *context.Context[In, Out]) {
-		res, ok := init(c)
+	c *context.Context[In, Out], fn Reducer[Out, In],
+	init func(*context.Context[In, Out]) (Out, bool),
+) {
+	res, ok := init(c)
+	if !ok {
+		return
+	}
+	c, restored, ok := context.WithState(c, "value", func() Out {
+		return res
+	})
+	if ok {
+		res = restored
+	}
+	for {
+		msg, ok := c.FetchMessage()
 		if !ok {
 			return
 		}
-		for {
-			msg, ok := c.FetchMessage()
-			if !ok {
-				return
-			}
 
-			res = fn(res, msg)
-			if !c.ForwardResult(res) {
-				return
-			}
+		res = fn(res, msg)
+		if !c.ForwardResult(res) {
+			return
 		}
 	}
 }
@@ -0,0 +1,62 @@
+package node
+
+import (
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+)
+
+// Buffered has the composite Processors within the provided Processor, such
+// as Bind, buffer the channels between the Processors they start to hold the
+// provided number of messages. This overrides the Stream's buffer size
+func Buffered[In, Out any](
+	size int, p stream.Processor[In, Out],
+) stream.Processor[In, Out] {
+	return stream.Composite(func(c *context.Context[In, Out]) {
+		p.Start(context.WithBuffer(c, size))
+	})
+}
+
+// Async constructs a Processor that decouples the Processors before it from
+// those after it, holding up to the provided number of messages while the
+// downstream is busy. Barriers are held in the same buffer as the messages,
+// so they're passed downstream in order
+func Async[Msg any](size int) stream.Processor[Msg, Msg] {
+	type item struct {
+		msg     Msg
+		barrier *context.Barrier
+	}
+
+	return func(c *context.Context[Msg, Msg]) {
+		buf := make(chan item, size)
+		c.WatchQueue(func() (int, int) {
+			return len(buf), cap(buf)
+		})
+
+		c.Go(func() {
+			for i := range buf {
+				if i.barrier != nil {
+					if !c.Checkpoint(i.barrier) {
+						return
+					}
+					continue
+				}
+				if !c.ForwardResult(i.msg) {
+					return
+				}
+			}
+		})
+		defer close(buf)
+
+		for {
+			msg, b, ok := c.Receive()
+			if !ok {
+				return
+			}
+			select {
+			case <-c.Done:
+				return
+			case buf <- item{msg: msg, barrier: b}:
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+func TestAsync(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan int)
+
+	node.Async[int](3).Start(
+		context.Make(done, make(chan context.Advice), in, out),
+	)
+
+	// the downstream isn't receiving, yet the upstream isn't blocked
+	for i := range 4 {
+		in <- i
+	}
+	for i := range 4 {
+		as.Equal(i, <-out)
+	}
+}
+
+func TestAsyncClosed(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan int)
+	finished := make(chan context.Done)
+
+	c := context.Make(done, make(chan context.Advice), in, out)
+	node.Async[int](2).Start(context.WithCompletion(c, func() {
+		close(finished)
+	}))
+
+	in <- 1
+	in <- 2
+	close(in)
+	as.Equal(1, <-out)
+	as.Equal(2, <-out)
+	<-finished
+}
+
+func TestBuffered(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan int)
+	seen := make(chan int, 3)
+	gate := make(chan struct{})
+
+	p := node.Buffered(2, node.Bind(
+		node.Forward[int],
+		node.ForEach(func(i int) {
+			<-gate
+			seen <- i
+		}),
+	))
+	p.Start(context.Make(done, make(chan context.Advice), in, out))
+	go func() {
+		for range out {
+		}
+	}()
+
+	// one message is held by ForEach and two more by the buffered hand-off,
+	// leaving one in Forward
+	for i := range 4 {
+		in <- i
+	}
+	close(gate)
+	for i := range 4 {
+		as.Equal(i, <-seen)
+	}
+}
@@ -1,6 +1,7 @@
 package node
 
 import (
+	"slices"
 	"time"
 
 	"github.com/kode4food/caravan/stream"
@@ -15,6 +16,12 @@ func Buffer[Msg any](
 ) stream.Processor[Msg, []Msg] {
 	return func(c *context.Context[Msg, []Msg]) {
 		batch := make([]Msg, 0, size)
+		c, restored, ok := context.WithState(c, "batch", func() []Msg {
+			return slices.Clone(batch)
+		})
+		if ok {
+			batch = append(batch, restored...)
+		}
 		timer := time.NewTimer(maxWait)
 		defer timer.Stop()
 
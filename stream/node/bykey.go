@@ -0,0 +1,133 @@
+package node
+
+import (
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/table"
+)
+
+type (
+	// KeyedValue is an update to the aggregate of a single Key, as emitted
+	// by the ByKey Processors
+	KeyedValue[Key comparable, Value any] struct {
+		Key   Key
+		Value Value
+	}
+
+	// Number is satisfied by the types that SumByKey is able to total
+	Number interface {
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+			~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+			~float32 | ~float64
+	}
+
+	// aggregate is the State kept for each Key by the ByKey Processors
+	aggregate[Agg any] struct {
+		Value Agg
+		Seen  bool
+	}
+)
+
+// ReduceByKey constructs a Processor that reduces the messages of each Group
+// separately. The first message of a Group becomes its aggregate, and each
+// message after it is combined with the aggregate using the provided function.
+// Every update is forwarded as a KeyedValue
+func ReduceByKey[Msg any, Key comparable](
+	fn Reducer[Msg, Msg],
+) stream.Processor[*Grouped[Msg, Key], KeyedValue[Key, Msg]] {
+	return func(c *context.Context[*Grouped[Msg, Key], KeyedValue[Key, Msg]]) {
+		byKey(c, func(agg *aggregate[Msg], msg Msg) {
+			if agg.Seen {
+				agg.Value = fn(agg.Value, msg)
+				return
+			}
+			agg.Value = msg
+		})
+	}
+}
+
+// ScanByKey constructs a Processor that applies a reducer function to the
+// messages of each Group separately, starting each Group from the initial
+// value. Every update is forwarded as a KeyedValue
+func ScanByKey[Msg any, Key comparable, Agg any](
+	fn Reducer[Agg, Msg], init Agg,
+) stream.Processor[*Grouped[Msg, Key], KeyedValue[Key, Agg]] {
+	return func(c *context.Context[*Grouped[Msg, Key], KeyedValue[Key, Agg]]) {
+		byKey(c, func(agg *aggregate[Agg], msg Msg) {
+			if !agg.Seen {
+				agg.Value = init
+			}
+			agg.Value = fn(agg.Value, msg)
+		})
+	}
+}
+
+// CountByKey constructs a Processor that counts the messages of each Group,
+// forwarding the updated count as a KeyedValue
+func CountByKey[Msg any, Key comparable]() stream.Processor[
+	*Grouped[Msg, Key], KeyedValue[Key, int],
+] {
+	return func(c *context.Context[*Grouped[Msg, Key], KeyedValue[Key, int]]) {
+		byKey(c, func(agg *aggregate[int], _ Msg) {
+			agg.Value++
+		})
+	}
+}
+
+// SumByKey constructs a Processor that totals the values selected from the
+// messages of each Group, forwarding the updated total as a KeyedValue
+func SumByKey[Msg any, Key comparable, Num Number](
+	value Mapper[Msg, Num],
+) stream.Processor[*Grouped[Msg, Key], KeyedValue[Key, Num]] {
+	return func(c *context.Context[*Grouped[Msg, Key], KeyedValue[Key, Num]]) {
+		byKey(c, func(agg *aggregate[Num], msg Msg) {
+			agg.Value += value(msg)
+		})
+	}
+}
+
+// TableUpsert constructs a Processor that materializes the KeyedValues it
+// sees into a Table using the provided Setter, and then forwards them. It's
+// meant to follow one of the ByKey Processors
+func TableUpsert[Key comparable, Value any](
+	set table.Setter[Key, Value],
+) stream.Processor[KeyedValue[Key, Value], KeyedValue[Key, Value]] {
+	return func(c *context.Context[
+		KeyedValue[Key, Value], KeyedValue[Key, Value],
+	]) {
+		for {
+			msg, ok := c.FetchMessage()
+			if !ok {
+				return
+			}
+
+			if e := set(msg.Key, msg.Value); e != nil {
+				if c.Error(e) {
+					continue
+				}
+				return
+			}
+
+			if !c.ForwardResult(msg) {
+				return
+			}
+		}
+	}
+}
+
+// byKey provides the common keyed aggregation loop for the ByKey Processors
+func byKey[Msg any, Key comparable, Agg any](
+	c *context.Context[*Grouped[Msg, Key], KeyedValue[Key, Agg]],
+	update func(*aggregate[Agg], Msg),
+) {
+	ProcessKeyed(GroupedKey[Msg, Key],
+		func(
+			agg *aggregate[Agg], g *Grouped[Msg, Key],
+			emit func(KeyedValue[Key, Agg]),
+		) {
+			update(agg, g.Message())
+			agg.Seen = true
+			emit(KeyedValue[Key, Agg]{Key: g.Key(), Value: agg.Value})
+		},
+	)(c)
+}
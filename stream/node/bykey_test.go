@@ -0,0 +1,128 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+type keyedInt = node.KeyedValue[string, int]
+
+func startByKey[Out any](
+	p stream.Processor[*node.Grouped[Event, string], Out],
+) (chan<- Event, <-chan Out, func()) {
+	done := make(chan context.Done)
+	in := make(chan Event)
+	out := make(chan Out)
+	node.Bind(
+		node.GroupBy(func(e Event) string { return e.UserID }),
+		p,
+	).Start(context.Make(done, make(chan context.Advice), in, out))
+	return in, out, func() { close(done) }
+}
+
+func TestCountByKey(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startByKey(node.CountByKey[Event, string]())
+	defer stop()
+
+	in <- Event{UserID: "alice"}
+	as.Equal(keyedInt{Key: "alice", Value: 1}, <-out)
+	in <- Event{UserID: "bob"}
+	as.Equal(keyedInt{Key: "bob", Value: 1}, <-out)
+	in <- Event{UserID: "alice"}
+	as.Equal(keyedInt{Key: "alice", Value: 2}, <-out)
+}
+
+func TestSumByKey(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startByKey(node.SumByKey[Event, string](
+		func(e Event) int { return e.Value },
+	))
+	defer stop()
+
+	in <- Event{UserID: "alice", Value: 3}
+	as.Equal(keyedInt{Key: "alice", Value: 3}, <-out)
+	in <- Event{UserID: "bob", Value: 5}
+	as.Equal(keyedInt{Key: "bob", Value: 5}, <-out)
+	in <- Event{UserID: "alice", Value: 4}
+	as.Equal(keyedInt{Key: "alice", Value: 7}, <-out)
+}
+
+func TestReduceByKey(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startByKey(node.ReduceByKey[Event, string](
+		func(l, r Event) Event {
+			if r.Value > l.Value {
+				return r
+			}
+			return l
+		},
+	))
+	defer stop()
+
+	in <- Event{UserID: "alice", Action: "a", Value: 3}
+	as.Equal("a", (<-out).Value.Action)
+	in <- Event{UserID: "alice", Action: "b", Value: 1}
+	as.Equal("a", (<-out).Value.Action)
+	in <- Event{UserID: "alice", Action: "c", Value: 9}
+	res := <-out
+	as.Equal("alice", res.Key)
+	as.Equal("c", res.Value.Action)
+}
+
+func TestScanByKey(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startByKey(node.ScanByKey[Event, string](
+		func(acts []string, e Event) []string {
+			return append(acts[:len(acts):len(acts)], e.Action)
+		}, []string{"start"},
+	))
+	defer stop()
+
+	in <- Event{UserID: "alice", Action: "login"}
+	as.Equal([]string{"start", "login"}, (<-out).Value)
+	in <- Event{UserID: "bob", Action: "click"}
+	as.Equal([]string{"start", "click"}, (<-out).Value)
+	in <- Event{UserID: "alice", Action: "logout"}
+	as.Equal([]string{"start", "login", "logout"}, (<-out).Value)
+}
+
+func TestTableUpsert(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := caravan.NewTable[string, int]("count")
+	as.Nil(err)
+	set, err := tbl.Setter("count")
+	as.Nil(err)
+
+	in, out, stop := startByKey(node.Bind(
+		node.CountByKey[Event, string](),
+		node.TableUpsert(set),
+	))
+	defer stop()
+
+	in <- Event{UserID: "alice"}
+	<-out
+	in <- Event{UserID: "alice"}
+	<-out
+	in <- Event{UserID: "bob"}
+	<-out
+
+	get, _ := tbl.Getter("count")
+	res, err := get("alice")
+	as.Nil(err)
+	as.Equal([]int{2}, res)
+	res, err = get("bob")
+	as.Nil(err)
+	as.Equal([]int{1}, res)
+}
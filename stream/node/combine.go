@@ -1,8 +1,11 @@
 package node
 
 import (
+	"slices"
+
 	"github.com/kode4food/caravan/stream"
 	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/topology"
 )
 
 // Pair represents a paired result from two streams
@@ -14,7 +17,11 @@ type Pair[Left, Right any] struct {
 // Bind the output of the left Processor to the input of the right Processor,
 // returning a new Processor that performs the handoff. If an error is reported
 // by the left Processor, the handoff will be short-circuited and the error
-// will be reported downstream.
+// will be reported downstream. If the left Processor completes on its own, the
+// handoff is closed so that the right Processor can complete. Once the right
+// Processor has returned, the left Processor is instructed to stop. Barriers
+// that have been checkpointed by the left Processor are passed on to the
+// right Processor.
 //
 // Processor[In, Out] = Processor[In, Bound] -> Processor[Bound, Out]
 //
@@ -24,11 +31,18 @@ type Pair[Left, Right any] struct {
 func Bind[In, Bound, Out any](
 	left stream.Processor[In, Bound], right stream.Processor[Bound, Out],
 ) stream.Processor[In, Out] {
-	return func(c *context.Context[In, Out]) {
-		h := make(chan Bound)
-		left.Start(context.WithOut(c, h))
-		right.Start(context.WithIn(c, h))
-	}
+	return stream.Composite(func(c *context.Context[In, Out]) {
+		c.Connect(topology.Sequence)
+		cl, cr := context.Chain(c, c)
+		rc, h := context.WithQueue[Bound](cr)
+		lc, cancel := context.WithCancel(context.WithOut(cl, h))
+		left.Start(context.WithCompletion(lc, func() {
+			if !lc.IsDone() {
+				close(h)
+			}
+		}))
+		right.Start(context.WithCompletion(rc, cancel))
+	})
 }
 
 // Subprocess is the internal implementation of a Subprocess
@@ -51,11 +65,12 @@ func Subprocess[Msg any](
 func Merge[Out any](
 	p ...stream.Processor[stream.Source, Out],
 ) stream.Processor[stream.Source, Out] {
-	return func(c *context.Context[stream.Source, Out]) {
-		for _, proc := range p {
-			proc.Start(c)
+	return stream.Composite(func(c *context.Context[stream.Source, Out]) {
+		c.Connect(topology.Parallel)
+		for i, fc := range context.Fork(c, len(p)) {
+			p[i].Start(fc)
 		}
-	}
+	})
 }
 
 // Zip combines two streams by strictly pairing their elements in order.
@@ -75,28 +90,17 @@ func ZipWith[Left, Right, Out any](
 	right stream.Processor[stream.Source, Right],
 	combiner BinaryOperator[Left, Right, Out],
 ) stream.Processor[stream.Source, Out] {
-	return func(c *context.Context[stream.Source, Out]) {
-		leftOut, rightOut := startBinaryProcessors(c, left, right)
-
-		for {
-			select {
-			case <-c.Done:
-				return
-			case leftMsg, ok := <-leftOut:
-				if !ok {
-					return
-				}
-				select {
-				case <-c.Done:
-					return
-				case rightMsg, ok := <-rightOut:
-					if !ok || !c.ForwardResult(combiner(leftMsg, rightMsg)) {
-						return
-					}
-				}
-			}
+	return stream.Composite(func(c *context.Context[stream.Source, Out]) {
+		leftOut, rightOut, jc, stop := startBinaryProcessors(c, left, right)
+		defer stop()
+		if jc.Describing() {
+			return
 		}
-	}
+
+		pairMessages(jc, leftOut, rightOut, func(l Left, r Right) bool {
+			return jc.ForwardResult(combiner(l, r))
+		})
+	})
 }
 
 // CombineLatest combines two streams by emitting whenever either stream
@@ -106,53 +110,59 @@ func CombineLatest[Left, Right, Out any](
 	right stream.Processor[stream.Source, Right],
 	combiner BinaryOperator[Left, Right, Out],
 ) stream.Processor[stream.Source, Out] {
-	return func(c *context.Context[stream.Source, Out]) {
-		leftOut, rightOut := startBinaryProcessors(c, left, right)
+	return stream.Composite(func(c *context.Context[stream.Source, Out]) {
+		leftOut, rightOut, c, stop := startBinaryProcessors(c, left, right)
+		defer stop()
+		if c.Describing() {
+			return
+		}
 
 		var (
-			latestLeft  Left
-			latestRight Right
-			hasLeft     bool
-			hasRight    bool
-			leftClosed  bool
-			rightClosed bool
+			latestLeft  *Left
+			latestRight *Right
 		)
+		c, l, _ := context.WithState(c, "left", func() *Left {
+			return latestLeft
+		})
+		c, r, _ := context.WithState(c, "right", func() *Right {
+			return latestRight
+		})
+		latestLeft, latestRight = l, r
 
-		for {
-			if leftClosed && rightClosed {
-				return
-			}
-
+		// a closed channel is replaced with nil so it's no longer selected
+		for leftOut != nil || rightOut != nil {
 			select {
 			case <-c.Done:
 				return
+			case b := <-c.Barriers():
+				if !c.Checkpoint(b) {
+					return
+				}
 			case leftMsg, ok := <-leftOut:
 				if !ok {
-					leftClosed = true
+					leftOut = nil
 					continue
 				}
-				latestLeft = leftMsg
-				hasLeft = true
-				if hasRight {
-					if !c.ForwardResult(combiner(latestLeft, latestRight)) {
+				latestLeft = &leftMsg
+				if latestRight != nil {
+					if !c.ForwardResult(combiner(leftMsg, *latestRight)) {
 						return
 					}
 				}
 			case rightMsg, ok := <-rightOut:
 				if !ok {
-					rightClosed = true
+					rightOut = nil
 					continue
 				}
-				latestRight = rightMsg
-				hasRight = true
-				if hasLeft {
-					if !c.ForwardResult(combiner(latestLeft, latestRight)) {
+				latestRight = &rightMsg
+				if latestLeft != nil {
+					if !c.ForwardResult(combiner(*latestLeft, rightMsg)) {
 						return
 					}
 				}
 			}
 		}
-	}
+	})
 }
 
 // Join accepts two Processors for the sake of joining their results based on a
@@ -165,50 +175,116 @@ func Join[Left, Right, Out any](
 	pred BinaryPredicate[Left, Right],
 	join BinaryOperator[Left, Right, Out],
 ) stream.Processor[stream.Source, Out] {
-	return func(c *context.Context[stream.Source, Out]) {
-		leftOut, rightOut := startBinaryProcessors(c, left, right)
-
-		joinResults := func() (Left, Right, bool) {
-			var leftZero Left
-			var rightZero Right
-			select {
-			case <-c.Done:
-				return leftZero, rightZero, false
-			case leftMsg := <-leftOut:
-				select {
-				case <-c.Done:
-					return leftZero, rightZero, false
-				case rightMsg := <-rightOut:
-					return leftMsg, rightMsg, true
-				}
-			case rightMsg := <-rightOut:
-				select {
-				case <-c.Done:
-					return leftZero, rightZero, false
-				case leftMsg := <-leftOut:
-					return leftMsg, rightMsg, true
-				}
-			}
+	return stream.Composite(func(c *context.Context[stream.Source, Out]) {
+		leftOut, rightOut, jc, stop := startBinaryProcessors(c, left, right)
+		defer stop()
+		if jc.Describing() {
+			return
 		}
 
-		for {
-			left, right, ok := joinResults()
-			if !ok || pred(left, right) && !c.ForwardResult(join(left, right)) {
-				return
-			}
-		}
-	}
+		pairMessages(jc, leftOut, rightOut, func(l Left, r Right) bool {
+			return !pred(l, r) || jc.ForwardResult(join(l, r))
+		})
+	})
 }
 
-// startBinaryProcessors sets up two processors with their output channels
+// startBinaryProcessors sets up two processors with their output channels.
+// Each channel is closed if its processor completes on its own. The returned
+// Context receives Barriers once both processors have checkpointed them, and
+// the returned function instructs both processors to stop
 func startBinaryProcessors[Left, Right, Out any](
 	c *context.Context[stream.Source, Out],
 	left stream.Processor[stream.Source, Left],
 	right stream.Processor[stream.Source, Right],
-) (chan Left, chan Right) {
-	leftOut := make(chan Left)
-	rightOut := make(chan Right)
-	left.Start(context.WithOut(c, leftOut))
-	right.Start(context.WithOut(c, rightOut))
-	return leftOut, rightOut
+) (chan Left, chan Right, *context.Context[stream.Source, Out], func()) {
+	c.Connect(topology.FanIn)
+	// the outputs are received from directly, alongside any Barriers, so
+	// they're only buffered when there are no Barriers to keep in order
+	var size int
+	if c.Barriers() == nil {
+		size = c.Buffer()
+	}
+	leftOut := make(chan Left, size)
+	rightOut := make(chan Right, size)
+	bc, cancel := context.WithCancel(c)
+	forks, jc := context.ForkJoin(bc, 2)
+	left.Start(context.WithCompletion(
+		context.WithOut(forks[0], leftOut), func() {
+			if !bc.IsDone() {
+				close(leftOut)
+			}
+		},
+	))
+	right.Start(context.WithCompletion(
+		context.WithOut(forks[1], rightOut), func() {
+			if !bc.IsDone() {
+				close(rightOut)
+			}
+		},
+	))
+	return leftOut, rightOut, jc, cancel
+}
+
+// pairMessages pairs the messages of two channels in the order they arrive,
+// until either channel is closed and has no queued messages left to pair.
+// Without Barriers, at most one message is queued on either side, so the
+// faster side waits for the slower. With Barriers, both sides are always
+// received from, so that neither is blocked while the other is aligning
+func pairMessages[Left, Right, Out any](
+	c *context.Context[stream.Source, Out], leftOut chan Left,
+	rightOut chan Right, pair func(Left, Right) bool,
+) {
+	var lefts []Left
+	var rights []Right
+	c, l, _ := context.WithState(c, "left", func() []Left {
+		return slices.Clone(lefts)
+	})
+	c, r, _ := context.WithState(c, "right", func() []Right {
+		return slices.Clone(rights)
+	})
+	lefts, rights = l, r
+
+	for {
+		for len(lefts) > 0 && len(rights) > 0 {
+			if !pair(lefts[0], rights[0]) {
+				return
+			}
+			lefts, rights = lefts[1:], rights[1:]
+		}
+		if leftOut == nil && len(lefts) == 0 ||
+			rightOut == nil && len(rights) == 0 {
+			return
+		}
+
+		lc, rc := leftOut, rightOut
+		if c.Barriers() == nil {
+			if len(lefts) > 0 {
+				lc = nil
+			}
+			if len(rights) > 0 {
+				rc = nil
+			}
+		}
+
+		select {
+		case <-c.Done:
+			return
+		case b := <-c.Barriers():
+			if !c.Checkpoint(b) {
+				return
+			}
+		case msg, ok := <-lc:
+			if !ok {
+				leftOut = nil
+				continue
+			}
+			lefts = append(lefts, msg)
+		case msg, ok := <-rc:
+			if !ok {
+				rightOut = nil
+				continue
+			}
+			rights = append(rights, msg)
+		}
+	}
 }
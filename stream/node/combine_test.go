@@ -329,6 +329,45 @@ func TestCombineLatest(t *testing.T) {
 	assert.Equal(t, "2-b", result3)
 }
 
+func TestCombineLatestClosedInput(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	out := make(chan string)
+	finished := make(chan context.Done)
+	leftDone := make(chan context.Done)
+
+	left := stream.Processor[stream.Source, int](
+		func(c *context.Context[stream.Source, int]) {
+			defer close(leftDone)
+			c.ForwardResult(1)
+		},
+	)
+	right := stream.Processor[stream.Source, string](
+		func(c *context.Context[stream.Source, string]) {
+			<-leftDone
+			c.ForwardResult("a")
+			c.ForwardResult("b")
+		},
+	)
+
+	p := node.CombineLatest(left, right, func(l int, r string) string {
+		return fmt.Sprintf("%d-%s", l, r)
+	})
+	p.Start(context.WithCompletion(
+		context.Make[stream.Source](
+			done, make(chan context.Advice), nil, out,
+		),
+		func() { close(finished) },
+	))
+
+	// the left side has closed, so only the right side is still selected
+	as.Equal("1-a", <-out)
+	as.Equal("1-b", <-out)
+	<-finished
+}
+
 // Tests for Join
 
 func joinGreaterThan(l int, r int) bool {
@@ -417,3 +456,63 @@ func TestJoinErrored(t *testing.T) {
 
 	as.Nil(s.Stop())
 }
+
+func TestBindCompletion(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	out := make(chan int)
+	finished := make(chan context.Done)
+
+	source := stream.Processor[stream.Source, int](
+		func(c *context.Context[stream.Source, int]) {
+			c.ForwardResult(1)
+			c.ForwardResult(2)
+		},
+	)
+	p := node.Bind(source, node.Map(func(i int) int { return i * 10 }))
+	p.Start(context.WithCompletion(
+		context.Make[stream.Source](
+			done, make(chan context.Advice), nil, out,
+		),
+		func() { close(finished) },
+	))
+
+	as.Equal(10, <-out)
+	as.Equal(20, <-out)
+	<-finished
+}
+
+func TestBindCancelsLeft(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan stream.Source)
+	out := make(chan int)
+	finished := make(chan context.Done)
+
+	p := node.Bind(
+		node.Generate(func() (int, bool) { return 1, true }),
+		node.Take[int](2),
+	)
+	p.Start(context.WithCompletion(
+		context.Make(done, make(chan context.Advice), in, out),
+		func() { close(finished) },
+	))
+
+	go func() {
+		for {
+			select {
+			case <-finished:
+				return
+			case in <- stream.Source{}:
+			}
+		}
+	}()
+
+	as.Equal(1, <-out)
+	as.Equal(1, <-out)
+	<-finished
+}
@@ -0,0 +1,294 @@
+package node
+
+import (
+	"slices"
+	"time"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+)
+
+type (
+	// Timestamp extracts the event time of a message
+	Timestamp[Msg any] func(Msg) time.Time
+
+	// EventTime configures the event-time windows. The watermark is the
+	// greatest event time seen, less the allowed Lateness. A window is
+	// emitted once the watermark passes its End, and a message whose
+	// windows have all been emitted is late
+	EventTime[Msg any] struct {
+		// Timestamp extracts the event time of each message
+		Timestamp Timestamp[Msg]
+
+		// Lateness is how far the watermark trails the greatest event time
+		// seen, giving out-of-order messages a chance to arrive
+		Lateness time.Duration
+
+		// Late receives the messages that arrive after their windows have
+		// been emitted. If it's nil, late messages are dropped
+		Late chan<- Msg
+	}
+
+	// EventWindow is a window of messages whose event times fall between
+	// its Start (inclusive) and End (exclusive)
+	EventWindow[Msg any] struct {
+		Start    time.Time
+		End      time.Time
+		Messages []Msg
+	}
+
+	// eventWindows is the checkpointed state of an event-time window
+	// Processor, holding the open windows of each Key
+	eventWindows[Key comparable, Msg any] struct {
+		Open   map[Key][]EventWindow[Msg]
+		Latest time.Time
+	}
+
+	// closedWindow is a window of a Key that the watermark has passed
+	closedWindow[Key comparable, Msg any] struct {
+		key    Key
+		window EventWindow[Msg]
+	}
+
+	// assigner places a message into the open windows of its Key. It
+	// returns true if the message is late, having arrived after each of the
+	// windows it falls within has closed. A message that falls within no
+	// window is dropped without being late
+	assigner[Msg any] func(
+		open []EventWindow[Msg], ts, watermark time.Time, msg Msg,
+	) ([]EventWindow[Msg], bool)
+)
+
+// TumblingEventWindow constructs a Processor that collects messages into
+// consecutive windows of a fixed size, based on their event time
+func TumblingEventWindow[Msg any](
+	et EventTime[Msg], size time.Duration,
+) stream.Processor[Msg, EventWindow[Msg]] {
+	return func(c *context.Context[Msg, EventWindow[Msg]]) {
+		eventWindow(c, et, hopping[Msg](size, size))
+	}
+}
+
+// HoppingEventWindow constructs a Processor that collects messages into
+// windows of a fixed size that begin every hop, based on their event time.
+// If the hop is smaller than the size, the windows overlap, and a message
+// is included in each window its event time falls within. If the hop is
+// larger, a message whose event time falls between windows is dropped, and
+// isn't passed to Late
+func HoppingEventWindow[Msg any](
+	et EventTime[Msg], size, hop time.Duration,
+) stream.Processor[Msg, EventWindow[Msg]] {
+	return func(c *context.Context[Msg, EventWindow[Msg]]) {
+		eventWindow(c, et, hopping[Msg](size, hop))
+	}
+}
+
+// SessionEventWindow constructs a Processor that collects messages into
+// sessions, based on their event time. A session is extended by each message
+// that arrives within the gap of another, and ends once the gap passes
+// without one
+func SessionEventWindow[Msg any](
+	et EventTime[Msg], gap time.Duration,
+) stream.Processor[Msg, EventWindow[Msg]] {
+	return func(c *context.Context[Msg, EventWindow[Msg]]) {
+		eventWindow(c, et, session[Msg](gap))
+	}
+}
+
+// eventWindow provides the common loop for the event-time window Processors
+func eventWindow[Msg any](
+	c *context.Context[Msg, EventWindow[Msg]], et EventTime[Msg],
+	assign assigner[Msg],
+) {
+	keyedEventWindow(c, et,
+		func(Msg) struct{} { return struct{}{} }, assign,
+		func(_ struct{}, w EventWindow[Msg]) bool {
+			return c.ForwardResult(w)
+		},
+	)
+}
+
+// keyedEventWindow provides the common loop for the keyed and unkeyed
+// event-time window Processors. The watermark is shared by every Key
+func keyedEventWindow[Msg any, Key comparable, Out any](
+	c *context.Context[Msg, Out], et EventTime[Msg],
+	key KeySelector[Msg, Key], assign assigner[Msg],
+	emit func(Key, EventWindow[Msg]) bool,
+) {
+	w := eventWindows[Key, Msg]{
+		Open: map[Key][]EventWindow[Msg]{},
+	}
+	c, restored, ok := context.WithState(c, "windows",
+		func() eventWindows[Key, Msg] {
+			return w.clone()
+		},
+	)
+	if ok {
+		w = restored.clone()
+	}
+
+	flush := func(watermark time.Time) bool {
+		for _, kw := range w.closed(watermark) {
+			if !emit(kw.key, kw.window) {
+				return false
+			}
+		}
+		return true
+	}
+
+	late := func(msg Msg) bool {
+		if et.Late == nil {
+			return true
+		}
+		select {
+		case <-c.Done:
+			return false
+		case et.Late <- msg:
+			return true
+		}
+	}
+
+	for {
+		msg, ok := c.FetchMessage()
+		if !ok {
+			// Flush the remaining windows on close
+			flush(maxTime)
+			return
+		}
+
+		ts := et.Timestamp(msg)
+		k := key(msg)
+		open, isLate := assign(w.Open[k], ts, w.watermark(et.Lateness), msg)
+		if isLate {
+			if !late(msg) {
+				return
+			}
+			continue
+		}
+		w.Open[k] = open
+		if !ts.After(w.Latest) {
+			continue
+		}
+		w.Latest = ts
+		if !flush(w.watermark(et.Lateness)) {
+			return
+		}
+	}
+}
+
+// maxTime is later than the End of any window
+var maxTime = time.Unix(1<<62, 0)
+
+func hopping[Msg any](size, hop time.Duration) assigner[Msg] {
+	return func(
+		open []EventWindow[Msg], ts, watermark time.Time, msg Msg,
+	) ([]EventWindow[Msg], bool) {
+		found, added := false, false
+		for start := ts.Truncate(hop); ts.Before(start.Add(size)); {
+			end := start.Add(size)
+			if end.After(watermark) {
+				open = addToWindow(open, start, end, msg)
+				added = true
+			}
+			found = true
+			start = start.Add(-hop)
+		}
+		return open, found && !added
+	}
+}
+
+func session[Msg any](gap time.Duration) assigner[Msg] {
+	return func(
+		open []EventWindow[Msg], ts, watermark time.Time, msg Msg,
+	) ([]EventWindow[Msg], bool) {
+		res := EventWindow[Msg]{Start: ts, End: ts.Add(gap)}
+		kept := make([]EventWindow[Msg], 0, len(open)+1)
+		for _, win := range open {
+			if !ts.Before(win.End) || !res.End.After(win.Start) {
+				kept = append(kept, win)
+				continue
+			}
+			if win.Start.Before(res.Start) {
+				res.Start = win.Start
+			}
+			if win.End.After(res.End) {
+				res.End = win.End
+			}
+			res.Messages = append(res.Messages, win.Messages...)
+		}
+		if !res.End.After(watermark) {
+			return open, true
+		}
+		res.Messages = append(res.Messages, msg)
+		return append(kept, res), false
+	}
+}
+
+// addToWindow includes the message in the window with the provided bounds,
+// opening the window if necessary
+func addToWindow[Msg any](
+	open []EventWindow[Msg], start, end time.Time, msg Msg,
+) []EventWindow[Msg] {
+	for i, win := range open {
+		if win.Start.Equal(start) && win.End.Equal(end) {
+			open[i].Messages = append(win.Messages, msg)
+			return open
+		}
+	}
+	return append(open, EventWindow[Msg]{
+		Start:    start,
+		End:      end,
+		Messages: []Msg{msg},
+	})
+}
+
+func (w *eventWindows[Key, Msg]) watermark(lateness time.Duration) time.Time {
+	return w.Latest.Add(-lateness)
+}
+
+func (w *eventWindows[Key, Msg]) clone() eventWindows[Key, Msg] {
+	res := eventWindows[Key, Msg]{
+		Open:   make(map[Key][]EventWindow[Msg], len(w.Open)),
+		Latest: w.Latest,
+	}
+	for k, open := range w.Open {
+		res.Open[k] = slices.Clone(open)
+	}
+	return res
+}
+
+// closed removes and returns the windows that the watermark has passed, in
+// the order of their End. Windows of different Keys that end together are
+// returned in no particular order
+func (w *eventWindows[Key, Msg]) closed(
+	watermark time.Time,
+) []closedWindow[Key, Msg] {
+	var res []closedWindow[Key, Msg]
+	for k, open := range w.Open {
+		if !slices.ContainsFunc(open, func(win EventWindow[Msg]) bool {
+			return !win.End.After(watermark)
+		}) {
+			continue
+		}
+		kept := make([]EventWindow[Msg], 0, len(open))
+		for _, win := range open {
+			if win.End.After(watermark) {
+				kept = append(kept, win)
+				continue
+			}
+			res = append(res, closedWindow[Key, Msg]{key: k, window: win})
+		}
+		if len(kept) == 0 {
+			delete(w.Open, k)
+			continue
+		}
+		w.Open[k] = kept
+	}
+	slices.SortStableFunc(res, func(l, r closedWindow[Key, Msg]) int {
+		if c := l.window.End.Compare(r.window.End); c != 0 {
+			return c
+		}
+		return l.window.Start.Compare(r.window.Start)
+	})
+	return res
+}
@@ -0,0 +1,196 @@
+package node_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+type reading struct {
+	at    time.Duration
+	value int
+}
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func readingTime(r reading) time.Time {
+	return epoch.Add(r.at)
+}
+
+func startEventWindow(
+	p stream.Processor[reading, node.EventWindow[reading]],
+) (chan reading, chan node.EventWindow[reading], func()) {
+	done := make(chan context.Done)
+	in := make(chan reading)
+	out := make(chan node.EventWindow[reading])
+	p.Start(context.Make(done, make(chan context.Advice), in, out))
+	return in, out, func() { close(done) }
+}
+
+func values(w node.EventWindow[reading]) []int {
+	res := make([]int, 0, len(w.Messages))
+	for _, r := range w.Messages {
+		res = append(res, r.value)
+	}
+	return res
+}
+
+func TestTumblingEventWindow(t *testing.T) {
+	as := assert.New(t)
+
+	late := make(chan reading, 1)
+	in, out, stop := startEventWindow(node.TumblingEventWindow(
+		node.EventTime[reading]{
+			Timestamp: readingTime,
+			Lateness:  3 * time.Second,
+			Late:      late,
+		}, 10*time.Second,
+	))
+	defer stop()
+
+	in <- reading{at: 1 * time.Second, value: 1}
+	in <- reading{at: 12 * time.Second, value: 2}
+	// out of order, but within the allowed lateness
+	in <- reading{at: 9500 * time.Millisecond, value: 3}
+	in <- reading{at: 13500 * time.Millisecond, value: 4}
+
+	w := <-out
+	as.Equal(epoch, w.Start)
+	as.Equal(epoch.Add(10*time.Second), w.End)
+	as.Equal([]int{1, 3}, values(w))
+
+	in <- reading{at: 5 * time.Second, value: 5}
+	as.Equal(5, (<-late).value)
+
+	close(in)
+	w = <-out
+	as.Equal(epoch.Add(10*time.Second), w.Start)
+	as.Equal([]int{2, 4}, values(w))
+}
+
+func TestHoppingEventWindow(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startEventWindow(node.HoppingEventWindow(
+		node.EventTime[reading]{Timestamp: readingTime},
+		10*time.Second, 5*time.Second,
+	))
+	defer stop()
+
+	in <- reading{at: 3 * time.Second, value: 1}
+	in <- reading{at: 7 * time.Second, value: 2}
+	w := <-out
+	as.Equal(epoch.Add(-5*time.Second), w.Start)
+	as.Equal([]int{1}, values(w))
+
+	in <- reading{at: 12 * time.Second, value: 3}
+	w = <-out
+	as.Equal(epoch, w.Start)
+	as.Equal([]int{1, 2}, values(w))
+
+	// late for the window at 0s, but not the one at 5s
+	in <- reading{at: 8 * time.Second, value: 4}
+	close(in)
+	w = <-out
+	as.Equal(epoch.Add(5*time.Second), w.Start)
+	as.Equal([]int{2, 3, 4}, values(w))
+	w = <-out
+	as.Equal(epoch.Add(10*time.Second), w.Start)
+	as.Equal([]int{3}, values(w))
+}
+
+func TestHoppingEventWindowGaps(t *testing.T) {
+	as := assert.New(t)
+
+	late := make(chan reading, 1)
+	in, out, stop := startEventWindow(node.HoppingEventWindow(
+		node.EventTime[reading]{
+			Timestamp: readingTime,
+			Late:      late,
+		}, 5*time.Second, 10*time.Second,
+	))
+	defer stop()
+
+	in <- reading{at: 2 * time.Second, value: 1}
+	// falls between the windows at 0s and 10s, but advances the watermark
+	in <- reading{at: 7 * time.Second, value: 2}
+	w := <-out
+	as.Equal(epoch, w.Start)
+	as.Equal([]int{1}, values(w))
+	as.Len(late, 0)
+
+	in <- reading{at: 3 * time.Second, value: 3}
+	as.Equal(3, (<-late).value)
+}
+
+func TestSessionEventWindow(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startEventWindow(node.SessionEventWindow(
+		node.EventTime[reading]{
+			Timestamp: readingTime,
+			Lateness:  10 * time.Second,
+		}, 6*time.Second,
+	))
+	defer stop()
+
+	in <- reading{at: 1 * time.Second, value: 1}
+	in <- reading{at: 10 * time.Second, value: 2}
+	// merges the two sessions
+	in <- reading{at: 5 * time.Second, value: 3}
+	in <- reading{at: 30 * time.Second, value: 4}
+
+	w := <-out
+	as.Equal(epoch.Add(time.Second), w.Start)
+	as.Equal(epoch.Add(16*time.Second), w.End)
+	as.Equal([]int{1, 2, 3}, values(w))
+
+	// late messages are dropped without a Late channel
+	in <- reading{at: 2 * time.Second, value: 5}
+	close(in)
+	w = <-out
+	as.Equal(epoch.Add(30*time.Second), w.Start)
+	as.Equal([]int{4}, values(w))
+}
+
+func TestEventWindowCheckpoint(t *testing.T) {
+	as := assert.New(t)
+
+	start := func(restored map[string]any) (
+		chan reading, chan node.EventWindow[reading],
+		chan *context.Barrier, chan *context.Barrier, func(),
+	) {
+		done := make(chan context.Done)
+		in := make(chan reading)
+		out := make(chan node.EventWindow[reading])
+		barrierIn := make(chan *context.Barrier)
+		barrierOut := make(chan *context.Barrier)
+		c := context.Make(done, make(chan context.Advice), in, out)
+		c = context.WithBarriers(c, barrierIn, barrierOut)
+		c = context.WithRestored(c, restored)
+		node.TumblingEventWindow(
+			node.EventTime[reading]{Timestamp: readingTime},
+			10*time.Second,
+		).Start(c)
+		return in, out, barrierIn, barrierOut, func() { close(done) }
+	}
+
+	in, _, barrierIn, barrierOut, stop := start(nil)
+	in <- reading{at: 1 * time.Second, value: 1}
+	b := context.MakeBarrier(1)
+	barrierIn <- b
+	as.Equal(b, <-barrierOut)
+	in <- reading{at: 2 * time.Second, value: 2}
+	stop()
+
+	in, out, _, _, stop := start(b.State())
+	defer stop()
+	in <- reading{at: 3 * time.Second, value: 3}
+	in <- reading{at: 10 * time.Second, value: 4}
+	as.Equal([]int{1, 3}, values(<-out))
+}
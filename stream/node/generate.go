@@ -21,8 +21,27 @@ func Generate[Msg any](
 }
 
 func GenerateFrom[Msg any](ch <-chan Msg) stream.Processor[stream.Source, Msg] {
-	return Generate(func() (Msg, bool) {
-		msg, ok := <-ch
-		return msg, ok
-	})
+	return func(c *context.Context[stream.Source, Msg]) {
+		for {
+			if _, ok := c.FetchMessage(); !ok {
+				return
+			}
+		receive:
+			select {
+			case <-c.Done:
+				return
+			case <-c.Draining():
+				return
+			case b := <-c.Barriers():
+				if !c.Checkpoint(b) {
+					return
+				}
+				goto receive
+			case msg, ok := <-ch:
+				if !ok || !c.ForwardResult(msg) {
+					return
+				}
+			}
+		}
+	}
 }
@@ -0,0 +1,127 @@
+package node
+
+import (
+	"maps"
+	"time"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+)
+
+type (
+	// KeyedFunc processes a message given the State of the message's Key,
+	// which it may update in place. Results passed to emit are forwarded
+	// downstream once the updated State has been stored. Checkpoints copy
+	// each State as is, so the State must be a value, such as a number or a
+	// struct of them. A map, slice or pointer that's updated in place would
+	// also change the checkpoints that were taken before the update
+	KeyedFunc[State, In, Out any] func(state *State, msg In, emit func(Out))
+
+	// keyedState is the checkpointed state of a keyed Processor
+	keyedState[Key comparable, State any] struct {
+		States  map[Key]State
+		Touched map[Key]time.Time
+	}
+)
+
+// ProcessKeyed constructs a Processor that keeps a separate State for each
+// Key selected from its messages. The States are kept in memory, and are
+// included in the Stream's checkpoints
+func ProcessKeyed[In any, Key comparable, State, Out any](
+	key KeySelector[In, Key], fn KeyedFunc[State, In, Out],
+) stream.Processor[In, Out] {
+	return func(c *context.Context[In, Out]) {
+		store := MakeMemoryStore[Key, State]()
+		ProcessKeyedWith(store, 0, key, fn)(c)
+	}
+}
+
+// ProcessKeyedWith constructs a Processor that keeps a separate State for each
+// Key selected from its messages in the provided StateStore. If the TTL is
+// greater than zero, the State of a Key that hasn't been seen within the TTL
+// expires, and the Key starts over from a zero State. Expired States are
+// removed from the store as messages arrive
+func ProcessKeyedWith[In any, Key comparable, State, Out any](
+	store StateStore[Key, State], ttl time.Duration,
+	key KeySelector[In, Key], fn KeyedFunc[State, In, Out],
+) stream.Processor[In, Out] {
+	return func(c *context.Context[In, Out]) {
+		mem, _ := store.(*MemoryStore[Key, State])
+		touched := map[Key]time.Time{}
+		c, restored, ok := context.WithState(c, "keyed",
+			func() keyedState[Key, State] {
+				res := keyedState[Key, State]{Touched: maps.Clone(touched)}
+				if mem != nil {
+					res.States = mem.snapshot()
+				}
+				return res
+			},
+		)
+		if ok {
+			touched = maps.Clone(restored.Touched)
+			if mem != nil {
+				mem.restore(restored.States)
+			}
+		}
+
+		expired := func(k Key, now time.Time) bool {
+			t, ok := touched[k]
+			return ttl > 0 && ok && now.Sub(t) >= ttl
+		}
+
+		swept := time.Now()
+		for {
+			msg, ok := c.FetchMessage()
+			if !ok {
+				return
+			}
+
+			now := time.Now()
+			if ttl > 0 && now.Sub(swept) >= ttl {
+				swept = now
+				for k := range touched {
+					if !expired(k, now) {
+						continue
+					}
+					if err := store.Delete(k); err != nil && !c.Error(err) {
+						return
+					}
+					delete(touched, k)
+				}
+			}
+
+			k := key(msg)
+			st, found, err := store.Get(k)
+			if err != nil {
+				if !c.Error(err) {
+					return
+				}
+				continue
+			}
+			if found && expired(k, now) {
+				var zero State
+				st = zero
+			}
+
+			var res []Out
+			fn(&st, msg, func(o Out) {
+				res = append(res, o)
+			})
+			if err := store.Put(k, st); err != nil {
+				if !c.Error(err) {
+					return
+				}
+				continue
+			}
+			if ttl > 0 {
+				touched[k] = now
+			}
+
+			for _, o := range res {
+				if !c.ForwardResult(o) {
+					return
+				}
+			}
+		}
+	}
+}
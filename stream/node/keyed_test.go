@@ -0,0 +1,140 @@
+package node_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+type visit struct {
+	user string
+	page string
+}
+
+func countVisits(count *int, v visit, emit func(string)) {
+	*count++
+	if *count%2 == 0 {
+		emit(v.user)
+	}
+}
+
+func visitUser(v visit) string {
+	return v.user
+}
+
+func TestProcessKeyed(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan visit)
+	out := make(chan string)
+
+	node.ProcessKeyed(visitUser, countVisits).Start(
+		context.Make(done, make(chan context.Advice), in, out),
+	)
+
+	in <- visit{user: "alice"}
+	in <- visit{user: "bob"}
+	in <- visit{user: "alice"}
+	as.Equal("alice", <-out)
+	in <- visit{user: "bob"}
+	as.Equal("bob", <-out)
+}
+
+func TestProcessKeyedTTL(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan visit)
+	out := make(chan int)
+	store := node.MakeMemoryStore[string, int]()
+
+	node.ProcessKeyedWith(store, 20*time.Millisecond, visitUser,
+		func(count *int, _ visit, emit func(int)) {
+			*count++
+			emit(*count)
+		},
+	).Start(context.Make(done, make(chan context.Advice), in, out))
+
+	in <- visit{user: "alice"}
+	as.Equal(1, <-out)
+	in <- visit{user: "bob"}
+	as.Equal(1, <-out)
+	in <- visit{user: "alice"}
+	as.Equal(2, <-out)
+
+	time.Sleep(30 * time.Millisecond)
+	in <- visit{user: "alice"}
+	as.Equal(1, <-out)
+
+	// bob expired and was swept from the store when alice arrived
+	_, ok, _ := store.Get("bob")
+	as.False(ok)
+	as.Equal(1, store.Len())
+}
+
+func TestProcessKeyedTable(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := caravan.NewTable[string, int]("visits")
+	as.Nil(err)
+	store, err := node.MakeTableStore(tbl, "visits")
+	as.Nil(err)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan visit)
+	out := make(chan string)
+
+	node.ProcessKeyedWith(store, 0, visitUser, countVisits).Start(
+		context.Make(done, make(chan context.Advice), in, out),
+	)
+
+	in <- visit{user: "alice"}
+	in <- visit{user: "alice"}
+	as.Equal("alice", <-out)
+
+	get, _ := tbl.Getter("visits")
+	res, err := get("alice")
+	as.Nil(err)
+	as.Equal([]int{2}, res)
+}
+
+func TestProcessKeyedCheckpoint(t *testing.T) {
+	as := assert.New(t)
+
+	start := func(restored map[string]any) (
+		chan visit, chan string, chan *context.Barrier,
+		chan *context.Barrier, func(),
+	) {
+		done := make(chan context.Done)
+		in := make(chan visit)
+		out := make(chan string)
+		barrierIn := make(chan *context.Barrier)
+		barrierOut := make(chan *context.Barrier)
+		c := context.Make(done, make(chan context.Advice), in, out)
+		c = context.WithBarriers(c, barrierIn, barrierOut)
+		c = context.WithRestored(c, restored)
+		node.ProcessKeyed(visitUser, countVisits).Start(c)
+		return in, out, barrierIn, barrierOut, func() { close(done) }
+	}
+
+	in, _, barrierIn, barrierOut, stop := start(nil)
+	in <- visit{user: "alice"}
+	b := context.MakeBarrier(1)
+	barrierIn <- b
+	as.Equal(b, <-barrierOut)
+	stop()
+
+	in, out, _, _, stop := start(b.State())
+	defer stop()
+	in <- visit{user: "alice"}
+	as.Equal("alice", <-out)
+}
@@ -0,0 +1,79 @@
+package node
+
+import (
+	"time"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+)
+
+// KeyedWindow is the aggregate of the messages of a single Key whose event
+// times fall between its Start (inclusive) and End (exclusive)
+type KeyedWindow[Key comparable, Agg any] struct {
+	Key   Key
+	Start time.Time
+	End   time.Time
+	Count int
+	Value Agg
+}
+
+// KeyedTumblingWindow constructs a Processor that collects the messages of
+// each Key into consecutive windows of a fixed size, based on their event
+// time. Each window is aggregated, starting from the initial value, when it's
+// forwarded
+func KeyedTumblingWindow[Msg any, Key comparable, Agg any](
+	et EventTime[Msg], key KeySelector[Msg, Key], size time.Duration,
+	fn Reducer[Agg, Msg], init Agg,
+) stream.Processor[Msg, KeyedWindow[Key, Agg]] {
+	return func(c *context.Context[Msg, KeyedWindow[Key, Agg]]) {
+		keyedWindow(c, et, key, hopping[Msg](size, size), fn, init)
+	}
+}
+
+// KeyedHoppingWindow constructs a Processor that collects the messages of
+// each Key into windows of a fixed size that begin every hop, based on their
+// event time. Each window is aggregated, starting from the initial value,
+// when it's forwarded
+func KeyedHoppingWindow[Msg any, Key comparable, Agg any](
+	et EventTime[Msg], key KeySelector[Msg, Key], size, hop time.Duration,
+	fn Reducer[Agg, Msg], init Agg,
+) stream.Processor[Msg, KeyedWindow[Key, Agg]] {
+	return func(c *context.Context[Msg, KeyedWindow[Key, Agg]]) {
+		keyedWindow(c, et, key, hopping[Msg](size, hop), fn, init)
+	}
+}
+
+// KeyedSessionWindow constructs a Processor that collects the messages of
+// each Key into sessions, based on their event time. Each session is
+// aggregated, starting from the initial value, when it's forwarded
+func KeyedSessionWindow[Msg any, Key comparable, Agg any](
+	et EventTime[Msg], key KeySelector[Msg, Key], gap time.Duration,
+	fn Reducer[Agg, Msg], init Agg,
+) stream.Processor[Msg, KeyedWindow[Key, Agg]] {
+	return func(c *context.Context[Msg, KeyedWindow[Key, Agg]]) {
+		keyedWindow(c, et, key, session[Msg](gap), fn, init)
+	}
+}
+
+// keyedWindow provides the common aggregation for the keyed window Processors
+func keyedWindow[Msg any, Key comparable, Agg any](
+	c *context.Context[Msg, KeyedWindow[Key, Agg]], et EventTime[Msg],
+	key KeySelector[Msg, Key], assign assigner[Msg],
+	fn Reducer[Agg, Msg], init Agg,
+) {
+	keyedEventWindow(c, et, key, assign,
+		func(k Key, w EventWindow[Msg]) bool {
+			res := init
+			for _, msg := range w.Messages {
+				res = fn(res, msg)
+			}
+			return c.ForwardResult(KeyedWindow[Key, Agg]{
+				Key:   k,
+				Start: w.Start,
+				End:   w.End,
+				Count: len(w.Messages),
+				Value: res,
+			})
+		},
+	)
+}
@@ -0,0 +1,137 @@
+package node_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+type click struct {
+	user string
+	at   time.Duration
+}
+
+type clickCount = node.KeyedWindow[string, int]
+
+var clickTime = node.EventTime[click]{
+	Timestamp: func(c click) time.Time { return epoch.Add(c.at) },
+}
+
+func clickUser(c click) string {
+	return c.user
+}
+
+func countClicks(count int, _ click) int {
+	return count + 1
+}
+
+func startKeyedWindow(
+	p stream.Processor[click, clickCount],
+) (chan click, chan clickCount, func()) {
+	done := make(chan context.Done)
+	in := make(chan click)
+	out := make(chan clickCount)
+	p.Start(context.Make(done, make(chan context.Advice), in, out))
+	return in, out, func() { close(done) }
+}
+
+func receiveCounts(out <-chan clickCount, n int) map[string]clickCount {
+	res := map[string]clickCount{}
+	for range n {
+		w := <-out
+		res[w.Key] = w
+	}
+	return res
+}
+
+func TestKeyedTumblingWindow(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startKeyedWindow(node.KeyedTumblingWindow(
+		clickTime, clickUser, time.Minute, countClicks, 0,
+	))
+	defer stop()
+
+	in <- click{user: "alice", at: 10 * time.Second}
+	in <- click{user: "bob", at: 20 * time.Second}
+	in <- click{user: "alice", at: 30 * time.Second}
+	in <- click{user: "alice", at: 70 * time.Second}
+
+	res := receiveCounts(out, 2)
+	as.Equal(clickCount{
+		Key:   "alice",
+		Start: epoch,
+		End:   epoch.Add(time.Minute),
+		Count: 2,
+		Value: 2,
+	}, res["alice"])
+	as.Equal(1, res["bob"].Value)
+
+	close(in)
+	w := <-out
+	as.Equal("alice", w.Key)
+	as.Equal(epoch.Add(time.Minute), w.Start)
+	as.Equal(1, w.Value)
+}
+
+func TestKeyedHoppingWindow(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startKeyedWindow(node.KeyedHoppingWindow(
+		clickTime, clickUser, time.Minute, 30*time.Second, countClicks, 0,
+	))
+	defer stop()
+
+	in <- click{user: "alice", at: 40 * time.Second}
+	in <- click{user: "bob", at: 50 * time.Second}
+	in <- click{user: "bob", at: 65 * time.Second}
+
+	// the windows from 0s to 60s have closed
+	res := receiveCounts(out, 2)
+	as.Equal(1, res["alice"].Value)
+	as.Equal(1, res["bob"].Value)
+	as.Equal(epoch, res["bob"].Start)
+
+	close(in)
+	res = receiveCounts(out, 2)
+	as.Equal(epoch.Add(30*time.Second), res["bob"].Start)
+	as.Equal(2, res["bob"].Value)
+	as.Equal(1, res["alice"].Value)
+	w := <-out
+	as.Equal(clickCount{
+		Key:   "bob",
+		Start: epoch.Add(time.Minute),
+		End:   epoch.Add(2 * time.Minute),
+		Count: 1,
+		Value: 1,
+	}, w)
+}
+
+func TestKeyedSessionWindow(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startKeyedWindow(node.KeyedSessionWindow(
+		clickTime, clickUser, 10*time.Second, countClicks, 0,
+	))
+	defer stop()
+
+	in <- click{user: "alice", at: 0}
+	in <- click{user: "bob", at: 5 * time.Second}
+	in <- click{user: "alice", at: 8 * time.Second}
+	in <- click{user: "bob", at: 30 * time.Second}
+
+	res := receiveCounts(out, 2)
+	as.Equal(2, res["alice"].Value)
+	as.Equal(epoch.Add(18*time.Second), res["alice"].End)
+	as.Equal(1, res["bob"].Value)
+
+	close(in)
+	w := <-out
+	as.Equal("bob", w.Key)
+	as.Equal(epoch.Add(30*time.Second), w.Start)
+}
@@ -74,6 +74,9 @@ func SkipWhile[Msg any](pred Predicate[Msg]) stream.Processor[Msg, Msg] {
 func Distinct[Msg any](eq Equality[Msg]) stream.Processor[Msg, Msg] {
 	return func(c *context.Context[Msg, Msg]) {
 		var last *Msg
+		c, last, _ = context.WithState(c, "last", func() *Msg {
+			return last
+		})
 
 		for {
 			msg, ok := c.FetchMessage()
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream/context"
 	"github.com/kode4food/caravan/stream/node"
 )
 
@@ -161,6 +162,33 @@ func TestDistinct(t *testing.T) {
 	}
 }
 
+func TestDistinctCheckpoint(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan int)
+	barrierIn := make(chan *context.Barrier)
+	barrierOut := make(chan *context.Barrier)
+
+	last := 2
+	c := context.Make(done, make(chan context.Advice), in, out)
+	c = context.WithBarriers(c, barrierIn, barrierOut)
+	c = context.WithRestored(c, map[string]any{"/last": &last})
+	node.Distinct(func(a, b int) bool { return a == b }).Start(c)
+
+	// the restored message is still considered the last one forwarded
+	in <- 2
+	in <- 3
+	as.Equal(3, <-out)
+
+	b := context.MakeBarrier(1)
+	barrierIn <- b
+	as.Equal(b, <-barrierOut)
+	as.Equal(3, *b.State()["/last"].(*int))
+}
+
 func TestDistinctBy(t *testing.T) {
 	type Person struct {
 		Name string
@@ -0,0 +1,219 @@
+package node
+
+import (
+	"cmp"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/table"
+)
+
+type (
+	// Aggregator incrementally maintains the aggregate of a group of
+	// messages. Add includes a message in the aggregate's State, and Remove
+	// retracts a message that was previously added. Neither may modify the
+	// State they're given, as it may be retained by a checkpoint. Value
+	// returns the aggregate of a State
+	Aggregator[Msg, State, Value any] struct {
+		Add    func(State, Msg) State
+		Remove func(State, Msg) State
+		Value  func(State) Value
+	}
+
+	// materialized is the State of a single row of a materialized view,
+	// along with the number of source rows it aggregates
+	materialized[State any] struct {
+		State State
+		Rows  int
+	}
+)
+
+// Materialize constructs a Processor that maintains an aggregate of the rows
+// of a source Table in a column of another Table, one row per Key selected
+// from the source rows. It consumes the source Table's change log, such as
+// from a TopicConsumer of its Changes. An inserted row is added to the
+// aggregate of its Key, a deleted row is retracted, and an updated row is
+// retracted and then added again, possibly to the aggregate of another Key.
+// Once every row of a Key has been retracted, the Key's row is deleted. Each
+// change is forwarded once it's been applied
+func Materialize[SrcKey comparable, Row any, Key comparable, State, Value any](
+	tbl table.Table[Key, Value], col table.ColumnName,
+	key KeySelector[[]Row, Key], agg Aggregator[[]Row, State, Value],
+) (stream.Processor[
+	table.Change[SrcKey, Row], table.Change[SrcKey, Row],
+], error) {
+	set, err := tbl.Setter(col)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *context.Context[
+		table.Change[SrcKey, Row], table.Change[SrcKey, Row],
+	]) {
+		rows := map[Key]materialized[State]{}
+		c, restored, ok := context.WithState(c, "rows",
+			func() map[Key]materialized[State] {
+				return maps.Clone(rows)
+			},
+		)
+		if ok {
+			rows = maps.Clone(restored)
+		}
+
+		add := func(row []Row) Key {
+			k := key(row)
+			m := rows[k]
+			m.State = agg.Add(m.State, row)
+			m.Rows++
+			rows[k] = m
+			return k
+		}
+
+		remove := func(row []Row) (Key, bool) {
+			k := key(row)
+			m, ok := rows[k]
+			if !ok {
+				// the row was added before the view was maintained
+				return k, false
+			}
+			m.State = agg.Remove(m.State, row)
+			m.Rows--
+			rows[k] = m
+			return k, true
+		}
+
+		update := func(k Key) error {
+			m := rows[k]
+			if m.Rows > 0 {
+				return set(k, agg.Value(m.State))
+			}
+			delete(rows, k)
+			err := tbl.Delete(k)
+			if errors.Is(err, table.ErrKeyNotFoundDelete) {
+				return nil
+			}
+			return err
+		}
+
+		apply := func(ch table.Change[SrcKey, Row]) error {
+			var touched []Key
+			if ch.Kind != table.Inserted && ch.Old != nil {
+				if k, ok := remove(ch.Old); ok {
+					touched = append(touched, k)
+				}
+			}
+			if ch.Kind != table.Deleted && ch.New != nil {
+				if k := add(ch.New); !slices.Contains(touched, k) {
+					touched = append(touched, k)
+				}
+			}
+			for _, k := range touched {
+				if err := update(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		for {
+			msg, ok := c.FetchMessage()
+			if !ok {
+				return
+			}
+
+			if e := apply(msg); e != nil {
+				if c.Error(e) {
+					continue
+				}
+				return
+			}
+
+			if !c.ForwardResult(msg) {
+				return
+			}
+		}
+	}, nil
+}
+
+// CountOf returns an Aggregator that counts messages
+func CountOf[Msg any]() Aggregator[Msg, int, int] {
+	return Aggregator[Msg, int, int]{
+		Add: func(count int, _ Msg) int {
+			return count + 1
+		},
+		Remove: func(count int, _ Msg) int {
+			return count - 1
+		},
+		Value: func(count int) int {
+			return count
+		},
+	}
+}
+
+// SumOf returns an Aggregator that totals the values selected from messages
+func SumOf[Msg any, Num Number](
+	value Mapper[Msg, Num],
+) Aggregator[Msg, Num, Num] {
+	return Aggregator[Msg, Num, Num]{
+		Add: func(sum Num, msg Msg) Num {
+			return sum + value(msg)
+		},
+		Remove: func(sum Num, msg Msg) Num {
+			return sum - value(msg)
+		},
+		Value: func(sum Num) Num {
+			return sum
+		},
+	}
+}
+
+// MinOf returns an Aggregator of the least of the values selected from
+// messages. Its State keeps every value, so that any may be retracted
+func MinOf[Msg any, Val cmp.Ordered](
+	value Mapper[Msg, Val],
+) Aggregator[Msg, []Val, Val] {
+	return sortedOf(value, func(s []Val) Val {
+		return s[0]
+	})
+}
+
+// MaxOf returns an Aggregator of the greatest of the values selected from
+// messages. Its State keeps every value, so that any may be retracted
+func MaxOf[Msg any, Val cmp.Ordered](
+	value Mapper[Msg, Val],
+) Aggregator[Msg, []Val, Val] {
+	return sortedOf(value, func(s []Val) Val {
+		return s[len(s)-1]
+	})
+}
+
+// sortedOf returns an Aggregator whose State is the sorted values selected
+// from messages
+func sortedOf[Msg any, Val cmp.Ordered](
+	value Mapper[Msg, Val], pick func([]Val) Val,
+) Aggregator[Msg, []Val, Val] {
+	return Aggregator[Msg, []Val, Val]{
+		Add: func(s []Val, msg Msg) []Val {
+			v := value(msg)
+			i, _ := slices.BinarySearch(s, v)
+			return slices.Insert(slices.Clone(s), i, v)
+		},
+		Remove: func(s []Val, msg Msg) []Val {
+			i, ok := slices.BinarySearch(s, value(msg))
+			if !ok {
+				return s
+			}
+			return slices.Delete(slices.Clone(s), i, i+1)
+		},
+		Value: func(s []Val) Val {
+			if len(s) == 0 {
+				var zero Val
+				return zero
+			}
+			return pick(s)
+		},
+	}
+}
@@ -0,0 +1,156 @@
+package node_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+	"github.com/kode4food/caravan/table"
+)
+
+type orderChange = table.Change[string, int]
+
+func orderCustomer(row []int) int {
+	return row[0]
+}
+
+func orderAmount(row []int) int {
+	return row[1]
+}
+
+func TestMaterialize(t *testing.T) {
+	as := assert.New(t)
+
+	orders, _ := caravan.NewTable[string, int]("customer", "amount")
+	set, _ := orders.Setter("customer", "amount")
+	totals, _ := caravan.NewTable[int, int]("total")
+	getTotal, _ := totals.Getter("total")
+
+	materialize, err := node.Materialize[string](
+		totals, "total", orderCustomer, node.SumOf(orderAmount),
+	)
+	as.Nil(err)
+
+	s := caravan.NewStream(
+		node.TopicConsumer(orders.Changes()),
+		materialize,
+	).Start()
+	defer func() { _ = s.Stop() }()
+
+	totalOf := func(customer int) int {
+		res, err := getTotal(customer)
+		if err != nil {
+			return -1
+		}
+		return res[0]
+	}
+	eventually := func(customer, total int) {
+		as.Eventually(func() bool {
+			return totalOf(customer) == total
+		}, time.Second, time.Millisecond)
+	}
+
+	as.Nil(set("o1", 1, 10))
+	as.Nil(set("o2", 1, 5))
+	as.Nil(set("o3", 2, 7))
+	eventually(1, 15)
+	eventually(2, 7)
+
+	// an updated row is retracted from one customer and added to another
+	as.Nil(set("o2", 2, 6))
+	eventually(1, 10)
+	eventually(2, 13)
+
+	// once every row of a customer is deleted, so is the customer's row
+	as.Nil(orders.Delete("o1"))
+	eventually(1, -1)
+	as.Equal(1, totals.Count())
+}
+
+func TestMaterializeAggregators(t *testing.T) {
+	as := assert.New(t)
+
+	for _, tc := range []struct {
+		name string
+		agg  node.Aggregator[[]int, []int, int]
+		res  []int
+	}{
+		{"min", node.MinOf(orderAmount), []int{5, 3, 5, 7}},
+		{"max", node.MaxOf(orderAmount), []int{5, 5, 7, 7}},
+	} {
+		totals, _ := caravan.NewTable[int, int]("value")
+		getValue, _ := totals.Getter("value")
+		materialize, err := node.Materialize[string](
+			totals, "value", orderCustomer, tc.agg,
+		)
+		as.Nil(err)
+
+		done := make(chan context.Done)
+		in := make(chan orderChange)
+		out := make(chan orderChange)
+		materialize.Start(
+			context.Make(done, make(chan context.Advice), in, out),
+		)
+
+		var res []int
+		apply := func(ch orderChange) {
+			in <- ch
+			<-out
+			v, err := getValue(1)
+			as.Nil(err)
+			res = append(res, v[0])
+		}
+		apply(orderChange{Kind: table.Inserted, Key: "o1", New: []int{1, 5}})
+		apply(orderChange{Kind: table.Inserted, Key: "o2", New: []int{1, 3}})
+		apply(orderChange{
+			Kind: table.Updated, Key: "o2",
+			Old: []int{1, 3}, New: []int{1, 7},
+		})
+		apply(orderChange{Kind: table.Deleted, Key: "o1", Old: []int{1, 5}})
+		as.Equal(tc.res, res, tc.name)
+		close(done)
+	}
+}
+
+func TestMaterializeCount(t *testing.T) {
+	as := assert.New(t)
+
+	counts, _ := caravan.NewTable[int, int]("count")
+	getCount, _ := counts.Getter("count")
+	materialize, err := node.Materialize[string](
+		counts, "count", orderCustomer, node.CountOf[[]int](),
+	)
+	as.Nil(err)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan orderChange)
+	out := make(chan orderChange)
+	materialize.Start(context.Make(done, make(chan context.Advice), in, out))
+
+	in <- orderChange{Kind: table.Inserted, Key: "o1", New: []int{1, 5}}
+	<-out
+	in <- orderChange{Kind: table.Inserted, Key: "o2", New: []int{1, 3}}
+	<-out
+	res, _ := getCount(1)
+	as.Equal([]int{2}, res)
+
+	// a retraction of a row that was never added is ignored
+	in <- orderChange{Kind: table.Deleted, Key: "o9", Old: []int{2, 1}}
+	<-out
+	as.Equal(1, counts.Count())
+}
+
+func TestMaterializeMissingColumn(t *testing.T) {
+	as := assert.New(t)
+
+	totals, _ := caravan.NewTable[int, int]("total")
+	_, err := node.Materialize[string](
+		totals, "missing", orderCustomer, node.CountOf[[]int](),
+	)
+	as.ErrorIs(err, table.ErrColumnNotFound)
+}
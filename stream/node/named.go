@@ -0,0 +1,16 @@
+package node
+
+import (
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+)
+
+// Named gives a name to the provided Processor, which identifies it in the
+// Stream's topology
+func Named[In, Out any](
+	name string, p stream.Processor[In, Out],
+) stream.Processor[In, Out] {
+	return stream.Composite(func(c *context.Context[In, Out]) {
+		p.Start(context.WithName(c, name))
+	})
+}
@@ -0,0 +1,34 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+func TestNamed(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan string)
+
+	p := node.Named("label", func(c *context.Context[int, string]) {
+		for {
+			if _, ok := c.FetchMessage(); !ok {
+				return
+			}
+			if !c.ForwardResult(c.Name()) {
+				return
+			}
+		}
+	})
+	p.Start(context.Make(done, make(chan context.Advice), in, out))
+
+	in <- 1
+	as.Equal("label", <-out)
+}
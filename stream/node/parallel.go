@@ -0,0 +1,165 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+)
+
+// Order determines the order in which ParallelMap forwards its results
+type Order int
+
+// Supported Orders
+const (
+	// Ordered forwards results in the order their messages were received,
+	// holding back those that finish early
+	Ordered Order = iota
+
+	// Unordered forwards results as soon as they're available
+	Unordered
+)
+
+// ErrMapperPanicked is reported when a ParallelMap function panics
+var ErrMapperPanicked = errors.New("parallel map function panicked")
+
+// ParallelMap constructs a Processor that maps messages using the provided
+// number of workers, each running the function in its own go routine. A
+// message whose function fails is dropped, and its error is reported to the
+// Stream as Error Advice. Barriers are passed downstream once every message
+// received before them has been forwarded or dropped
+func ParallelMap[From, To any](
+	workers int, order Order, fn ErrMapper[From, To],
+) stream.Processor[From, To] {
+	type (
+		job struct {
+			seq uint64
+			msg From
+		}
+
+		result struct {
+			seq uint64
+			res To
+			err error
+		}
+
+		mark struct {
+			barrier *context.Barrier
+			after   uint64
+		}
+	)
+
+	apply := func(msg From) (res To, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = fmt.Errorf("%w: %v", ErrMapperPanicked, v)
+			}
+		}()
+		return fn(msg)
+	}
+
+	return func(c *context.Context[From, To]) {
+		jobs := make(chan job)
+		results := make(chan result, max(1, workers))
+		marks := make(chan mark)
+
+		var wg sync.WaitGroup
+		for range max(1, workers) {
+			wg.Go(func() {
+				for j := range jobs {
+					res, err := apply(j.msg)
+					select {
+					case <-c.Done:
+						return
+					case results <- result{seq: j.seq, res: res, err: err}:
+					}
+				}
+			})
+		}
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		c.Go(func() {
+			var emitted uint64
+			var held []mark
+			pending := map[uint64]result{}
+			emit := func(r result) bool {
+				emitted++
+				if r.err != nil {
+					return c.Error(r.err)
+				}
+				return c.ForwardResult(r.res)
+			}
+
+			for {
+				for len(held) > 0 && emitted >= held[0].after {
+					if !c.Checkpoint(held[0].barrier) {
+						return
+					}
+					held = held[1:]
+				}
+				select {
+				case <-c.Done:
+					return
+				case m := <-marks:
+					held = append(held, m)
+				case r, ok := <-results:
+					if !ok {
+						// every result has been emitted, so no Barrier
+						// is waiting on another
+						for _, m := range held {
+							if !c.Checkpoint(m.barrier) {
+								return
+							}
+						}
+						return
+					}
+					if order == Unordered {
+						if !emit(r) {
+							return
+						}
+						continue
+					}
+					pending[r.seq] = r
+					for {
+						next, ok := pending[emitted]
+						if !ok {
+							break
+						}
+						delete(pending, emitted)
+						if !emit(next) {
+							return
+						}
+					}
+				}
+			}
+		})
+		defer close(jobs)
+
+		var seq uint64
+		for {
+			msg, b, ok := c.Receive()
+			if !ok {
+				return
+			}
+			if b != nil {
+				select {
+				case <-c.Done:
+					return
+				case marks <- mark{barrier: b, after: seq}:
+				}
+				continue
+			}
+			select {
+			case <-c.Done:
+				return
+			case jobs <- job{seq: seq, msg: msg}:
+				seq++
+			}
+		}
+	}
+}
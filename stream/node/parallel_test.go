@@ -0,0 +1,127 @@
+package node_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+// slowFirst delays the early messages the longest, so that later messages
+// finish first when run in parallel
+func slowFirst(i int) (int, error) {
+	time.Sleep(time.Duration(5-i) * 5 * time.Millisecond)
+	return i * 10, nil
+}
+
+func TestParallelMapOrdered(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan int)
+
+	node.ParallelMap(5, node.Ordered, slowFirst).Start(
+		context.Make(done, make(chan context.Advice), in, out),
+	)
+
+	go func() {
+		for i := range 5 {
+			in <- i
+		}
+	}()
+	for i := range 5 {
+		as.Equal(i*10, <-out)
+	}
+}
+
+func TestParallelMapUnordered(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan int)
+
+	node.ParallelMap(5, node.Unordered, slowFirst).Start(
+		context.Make(done, make(chan context.Advice), in, out),
+	)
+
+	go func() {
+		for i := range 5 {
+			in <- i
+		}
+	}()
+	var res []int
+	for range 5 {
+		res = append(res, <-out)
+	}
+	as.NotEqual([]int{0, 10, 20, 30, 40}, res)
+	slices.Sort(res)
+	as.Equal([]int{0, 10, 20, 30, 40}, res)
+}
+
+func TestParallelMapErrors(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	monitor := make(chan context.Advice)
+	in := make(chan int)
+	out := make(chan int)
+	finished := make(chan context.Done)
+
+	err := errors.New("odd")
+	p := node.ParallelMap(2, node.Ordered, func(i int) (int, error) {
+		switch {
+		case i == 3:
+			panic("three")
+		case i%2 == 1:
+			return 0, err
+		default:
+			return i, nil
+		}
+	})
+	c := context.Make(done, monitor, in, out)
+	p.Start(context.WithCompletion(c, func() {
+		close(finished)
+	}))
+
+	go func() {
+		for i := range 4 {
+			in <- i
+		}
+		close(in)
+	}()
+
+	as.Equal(0, <-out)
+	as.ErrorIs((<-monitor).(*context.Error), err)
+	as.Equal(2, <-out)
+	as.ErrorIs((<-monitor).(*context.Error), node.ErrMapperPanicked)
+	<-finished
+}
+
+func TestParallelMapDone(t *testing.T) {
+	done := make(chan context.Done)
+	in := make(chan int)
+	out := make(chan int)
+	finished := make(chan context.Done)
+
+	c := context.Make(done, make(chan context.Advice), in, out)
+	node.ParallelMap(2, node.Ordered, func(i int) (int, error) {
+		return i, nil
+	}).Start(context.WithCompletion(c, func() {
+		close(finished)
+	}))
+
+	in <- 1
+	in <- 2
+	close(done)
+	<-finished
+}
@@ -0,0 +1,253 @@
+package node
+
+import (
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+)
+
+type (
+	// Result is either a value or the error that prevented it from being
+	// produced. It allows errors to flow through a Stream as messages, to be
+	// routed by Partition or RouteErrors
+	Result[T any] struct {
+		Value T
+		Err   error
+	}
+
+	// ErrorPolicy determines how the error-aware Processors, such as MapErr,
+	// respond to their function failing. In all cases, the failed message
+	// isn't forwarded
+	ErrorPolicy int
+)
+
+// Supported ErrorPolicies
+const (
+	// AdviseErrors reports the error as Error Advice
+	AdviseErrors ErrorPolicy = iota
+
+	// DropErrors silently drops the failed message
+	DropErrors
+
+	// FailOnError reports the error as Fatal Advice, stopping the Stream
+	FailOnError
+)
+
+// Ok returns a successful Result
+func Ok[T any](value T) Result[T] {
+	return Result[T]{Value: value}
+}
+
+// Failed returns an unsuccessful Result
+func Failed[T any](err error) Result[T] {
+	return Result[T]{Err: err}
+}
+
+// IsOk returns whether the Result holds a value rather than an error
+func (r Result[T]) IsOk() bool {
+	return r.Err == nil
+}
+
+// MapErr constructs a Processor that maps messages using a function that may
+// fail. Failures are handled according to the provided ErrorPolicy
+func MapErr[From, To any](
+	fn ErrMapper[From, To], policy ErrorPolicy,
+) stream.Processor[From, To] {
+	return func(c *context.Context[From, To]) {
+		for {
+			msg, ok := c.FetchMessage()
+			if !ok {
+				return
+			}
+			res, err := fn(msg)
+			if err != nil {
+				if !handleError(c, err, policy) {
+					return
+				}
+				continue
+			}
+			if !c.ForwardResult(res) {
+				return
+			}
+		}
+	}
+}
+
+// FilterErr constructs a Processor that only forwards its messages if the
+// provided function returns true. Failures are handled according to the
+// provided ErrorPolicy
+func FilterErr[Msg any](
+	fn func(Msg) (bool, error), policy ErrorPolicy,
+) stream.Processor[Msg, Msg] {
+	return func(c *context.Context[Msg, Msg]) {
+		for {
+			msg, ok := c.FetchMessage()
+			if !ok {
+				return
+			}
+			keep, err := fn(msg)
+			if err != nil {
+				if !handleError(c, err, policy) {
+					return
+				}
+				continue
+			}
+			if keep && !c.ForwardResult(msg) {
+				return
+			}
+		}
+	}
+}
+
+// FlatMapErr constructs a Processor that maps each message to zero or more
+// messages using a function that may fail. Failures are handled according to
+// the provided ErrorPolicy, and none of the failed message's results are
+// forwarded
+func FlatMapErr[From, To any](
+	fn func(From) ([]To, error), policy ErrorPolicy,
+) stream.Processor[From, To] {
+	return func(c *context.Context[From, To]) {
+		for {
+			msg, ok := c.FetchMessage()
+			if !ok {
+				return
+			}
+			res, err := fn(msg)
+			if err != nil {
+				if !handleError(c, err, policy) {
+					return
+				}
+				continue
+			}
+			for _, r := range res {
+				if !c.ForwardResult(r) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// MapResult constructs a Processor that maps messages into Results using a
+// function that may fail, so that failures can be routed by Partition
+func MapResult[From, To any](
+	fn ErrMapper[From, To],
+) stream.Processor[From, Result[To]] {
+	return Map(func(msg From) Result[To] {
+		res, err := fn(msg)
+		if err != nil {
+			return Failed[To](err)
+		}
+		return Ok(res)
+	})
+}
+
+// FilterResult constructs a Processor that forwards a successful Result for
+// each message that the provided function keeps, and an unsuccessful Result
+// for each message that it fails to judge
+func FilterResult[Msg any](
+	fn func(Msg) (bool, error),
+) stream.Processor[Msg, Result[Msg]] {
+	return FlatMap(func(msg Msg) []Result[Msg] {
+		keep, err := fn(msg)
+		switch {
+		case err != nil:
+			return []Result[Msg]{Failed[Msg](err)}
+		case keep:
+			return []Result[Msg]{Ok(msg)}
+		default:
+			return nil
+		}
+	})
+}
+
+// FlatMapResult constructs a Processor that maps each message to zero or more
+// successful Results using a function that may fail. A failed message is
+// mapped to a single unsuccessful Result, and none of its results are
+// forwarded
+func FlatMapResult[From, To any](
+	fn func(From) ([]To, error),
+) stream.Processor[From, Result[To]] {
+	return FlatMap(func(msg From) []Result[To] {
+		res, err := fn(msg)
+		if err != nil {
+			return []Result[To]{Failed[To](err)}
+		}
+		out := make([]Result[To], len(res))
+		for i, r := range res {
+			out[i] = Ok(r)
+		}
+		return out
+	})
+}
+
+// RouteErrors constructs a Processor that forwards the values of successful
+// Results, and routes the errors of unsuccessful Results to the provided
+// Processor. Unlike Partition, the Stream continues past it with the values
+func RouteErrors[T, ErrOut any](
+	failed stream.Processor[error, ErrOut],
+) stream.Processor[Result[T], T] {
+	route := fanOut(Forward[T],
+		values[T](),
+		Bind(errs[T](), Bind(failed, discard[ErrOut, T]())),
+	)
+	return stream.Composite(func(c *context.Context[Result[T], T]) {
+		route(c)
+	})
+}
+
+// Partition constructs a Processor that routes the values of successful
+// Results to the first Processor, and the errors of unsuccessful Results to
+// the second
+func Partition[T, OkOut, ErrOut any](
+	ok stream.Processor[T, OkOut], failed stream.Processor[error, ErrOut],
+) stream.Processor[Result[T], stream.Sink] {
+	split := Split(
+		Bind(values[T](), Bind(ok, discard[OkOut, stream.Sink]())),
+		Bind(errs[T](), Bind(failed, discard[ErrOut, stream.Sink]())),
+	)
+	return stream.Composite(func(c *context.Context[Result[T], stream.Sink]) {
+		split(c)
+	})
+}
+
+func values[T any]() stream.Processor[Result[T], T] {
+	return FlatMap(func(r Result[T]) []T {
+		if r.IsOk() {
+			return []T{r.Value}
+		}
+		return nil
+	})
+}
+
+func errs[T any]() stream.Processor[Result[T], error] {
+	return FlatMap(func(r Result[T]) []error {
+		if !r.IsOk() {
+			return []error{r.Err}
+		}
+		return nil
+	})
+}
+
+// discard drops the messages of a branch so that the branches can be
+// combined, which requires them to produce the same type
+func discard[Msg, Out any]() stream.Processor[Msg, Out] {
+	return FlatMap(func(Msg) []Out {
+		return nil
+	})
+}
+
+// handleError responds to a failed message according to the ErrorPolicy,
+// returning whether the Processor should continue
+func handleError[In, Out any](
+	c *context.Context[In, Out], err error, policy ErrorPolicy,
+) bool {
+	switch policy {
+	case DropErrors:
+		return true
+	case FailOnError:
+		c.Fatal(err)
+		return false
+	default:
+		return c.Error(err)
+	}
+}
@@ -0,0 +1,232 @@
+package node_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+var errOdd = errors.New("odd")
+
+func evenOnly(i int) (string, error) {
+	if i%2 == 1 {
+		return "", errOdd
+	}
+	return strconv.Itoa(i), nil
+}
+
+func TestMapErr(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	monitor := make(chan context.Advice)
+	in := make(chan int)
+	out := make(chan string)
+
+	node.MapErr(evenOnly, node.AdviseErrors).Start(
+		context.Make(done, monitor, in, out),
+	)
+
+	in <- 2
+	as.Equal("2", <-out)
+	in <- 3
+	as.ErrorIs((<-monitor).(*context.Error), errOdd)
+	in <- 4
+	as.Equal("4", <-out)
+}
+
+func TestMapErrDrop(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan string)
+
+	node.MapErr(evenOnly, node.DropErrors).Start(
+		context.Make(done, make(chan context.Advice), in, out),
+	)
+
+	in <- 1
+	in <- 3
+	in <- 4
+	as.Equal("4", <-out)
+}
+
+func TestMapErrFail(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	monitor := make(chan context.Advice)
+	in := make(chan int)
+	out := make(chan string)
+	finished := make(chan context.Done)
+
+	c := context.Make(done, monitor, in, out)
+	node.MapErr(evenOnly, node.FailOnError).Start(
+		context.WithCompletion(c, func() { close(finished) }),
+	)
+
+	in <- 1
+	as.ErrorIs((<-monitor).(*context.Fatal), errOdd)
+	<-finished
+}
+
+func TestFilterErr(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	monitor := make(chan context.Advice)
+	in := make(chan int)
+	out := make(chan int)
+
+	node.FilterErr(func(i int) (bool, error) {
+		if i < 0 {
+			return false, errOdd
+		}
+		return i > 1, nil
+	}, node.AdviseErrors).Start(context.Make(done, monitor, in, out))
+
+	in <- 1
+	in <- -1
+	as.ErrorIs((<-monitor).(*context.Error), errOdd)
+	in <- 2
+	as.Equal(2, <-out)
+}
+
+func TestFlatMapErr(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan int)
+
+	node.FlatMapErr(func(i int) ([]int, error) {
+		if i%2 == 1 {
+			return []int{i}, errOdd
+		}
+		return []int{i, i}, nil
+	}, node.DropErrors).Start(
+		context.Make(done, make(chan context.Advice), in, out),
+	)
+
+	in <- 1
+	in <- 2
+	as.Equal(2, <-out)
+	as.Equal(2, <-out)
+}
+
+func TestPartition(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	oks := make(chan string, 2)
+	errs := make(chan error, 2)
+
+	p := node.Bind(
+		node.MapResult(evenOnly),
+		node.Partition(node.SidechainTo(oks), node.SidechainTo(errs)),
+	)
+	p.Start(context.Make(
+		done, make(chan context.Advice), in, make(chan stream.Sink),
+	))
+
+	for i := range 4 {
+		in <- i
+	}
+	as.Equal("0", <-oks)
+	as.Equal("2", <-oks)
+	as.ErrorIs(<-errs, errOdd)
+	as.ErrorIs(<-errs, errOdd)
+}
+
+func TestRouteErrors(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan string)
+	errs := make(chan error, 2)
+
+	p := node.Bind(
+		node.MapResult(evenOnly),
+		node.RouteErrors[string](node.SidechainTo(errs)),
+	)
+	p.Start(context.Make(done, make(chan context.Advice), in, out))
+
+	go func() {
+		for i := range 4 {
+			in <- i
+		}
+	}()
+	as.Equal("0", <-out)
+	as.Equal("2", <-out)
+	as.ErrorIs(<-errs, errOdd)
+	as.ErrorIs(<-errs, errOdd)
+}
+
+func TestFilterResult(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan node.Result[int])
+
+	node.FilterResult(func(i int) (bool, error) {
+		if i < 0 {
+			return false, errOdd
+		}
+		return i > 1, nil
+	}).Start(context.Make(done, make(chan context.Advice), in, out))
+
+	in <- 1
+	in <- -1
+	as.ErrorIs((<-out).Err, errOdd)
+	in <- 2
+	as.Equal(node.Ok(2), <-out)
+}
+
+func TestFlatMapResult(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan node.Result[int])
+
+	node.FlatMapResult(func(i int) ([]int, error) {
+		if i%2 == 1 {
+			return []int{i}, errOdd
+		}
+		return []int{i, i}, nil
+	}).Start(context.Make(done, make(chan context.Advice), in, out))
+
+	in <- 1
+	as.ErrorIs((<-out).Err, errOdd)
+	in <- 2
+	as.Equal(node.Ok(2), <-out)
+	as.Equal(node.Ok(2), <-out)
+}
+
+func TestResult(t *testing.T) {
+	as := assert.New(t)
+
+	as.True(node.Ok(1).IsOk())
+	r := node.Failed[int](errOdd)
+	as.False(r.IsOk())
+	as.Equal(errOdd, r.Err)
+}
@@ -6,23 +6,62 @@ import (
 
 	"github.com/kode4food/caravan/stream"
 	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/topology"
 )
 
 func Split[In, Out any](
 	p ...stream.Processor[In, Out],
 ) stream.Processor[In, stream.Sink] {
-	return func(c *context.Context[In, stream.Sink]) {
-		sink := make(chan Out)
-		Sink[Out]().Start(
-			context.With(c, sink, make(chan stream.Sink)),
-		)
+	split := fanOut(Sink[Out](), p...)
+	return stream.Composite(func(c *context.Context[In, stream.Sink]) {
+		split(c)
+	})
+}
+
+// fanOut forwards each message to all the provided Processors, and passes
+// their combined results to the join Processor, which produces the results of
+// the returned Processor
+func fanOut[In, Out, Res any](
+	join stream.Processor[Out, Res], p ...stream.Processor[In, Out],
+) stream.Processor[In, Res] {
+	return stream.Composite(func(c *context.Context[In, Res]) {
+		c.Connect(topology.FanOut)
+		c, fc := context.Chain(c, c)
+		forks, jc := context.ForkJoin(fc, len(p))
+		sc, sink := context.WithQueue[Out](jc)
 
+		if !c.Describing() {
+			// not started as a Processor of its own, so that it stays out of
+			// the Stream's metrics
+			sc.Go(func() {
+				join(sc)
+			})
+		}
+
+		var running atomic.Int32
+		running.Store(int32(len(p)))
 		handoff := make([]chan In, len(p))
 		for i, proc := range p {
-			ch := make(chan In)
+			qc, ch := context.WithQueue[In](forks[i])
 			handoff[i] = ch
-			proc.Start(context.With(c, ch, sink))
+			procs := context.WithCompletion(qc, func() {
+				if running.Add(-1) == 0 && !c.IsDone() {
+					close(sink)
+				}
+			})
+			proc.Start(context.WithOut(procs, sink))
+		}
+		if c.Describing() {
+			return
 		}
+		defer func() {
+			if c.IsDone() {
+				return
+			}
+			for _, ch := range handoff {
+				close(ch)
+			}
+		}()
 
 		forwardInput := func(msg In) bool {
 			var isDone atomic.Bool
@@ -49,5 +88,5 @@ func Split[In, Out any](
 				return
 			}
 		}
-	}
+	})
 }
@@ -0,0 +1,139 @@
+package node
+
+import (
+	"errors"
+	"maps"
+	"sync"
+
+	"github.com/kode4food/caravan/table"
+)
+
+type (
+	// StateStore holds the state of a keyed Processor, one State per Key
+	StateStore[Key comparable, State any] interface {
+		// Get returns the State of the Key, if there is one
+		Get(Key) (State, bool, error)
+
+		// Put replaces the State of the Key
+		Put(Key, State) error
+
+		// Delete removes the State of the Key, if there is one
+		Delete(Key) error
+	}
+
+	// MemoryStore is a StateStore that keeps its States in memory. Its
+	// States are included in the Stream's checkpoints
+	MemoryStore[Key comparable, State any] struct {
+		states map[Key]State
+		mu     sync.RWMutex
+	}
+
+	// TableStore is a StateStore that keeps its States in a column of a
+	// Table, so that they can be queried from outside the Stream. Like any
+	// Table update, its States aren't included in the Stream's checkpoints
+	TableStore[Key comparable, State any] struct {
+		tbl table.Table[Key, State]
+		get table.Getter[Key, State]
+		set table.Setter[Key, State]
+	}
+)
+
+// MakeMemoryStore instantiates a new MemoryStore
+func MakeMemoryStore[Key comparable, State any]() *MemoryStore[Key, State] {
+	return &MemoryStore[Key, State]{
+		states: map[Key]State{},
+	}
+}
+
+// Get returns the State of the Key, if there is one
+func (s *MemoryStore[Key, State]) Get(k Key) (State, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res, ok := s.states[k]
+	return res, ok, nil
+}
+
+// Put replaces the State of the Key
+func (s *MemoryStore[Key, State]) Put(k Key, st State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[k] = st
+	return nil
+}
+
+// Delete removes the State of the Key, if there is one
+func (s *MemoryStore[Key, State]) Delete(k Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, k)
+	return nil
+}
+
+// Len returns the number of Keys that have a State
+func (s *MemoryStore[_, _]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.states)
+}
+
+// snapshot copies the States, but not what they refer to, which is why a
+// KeyedFunc's State must be a value
+func (s *MemoryStore[Key, State]) snapshot() map[Key]State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.states)
+}
+
+func (s *MemoryStore[Key, State]) restore(states map[Key]State) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states = maps.Clone(states)
+}
+
+// MakeTableStore instantiates a new TableStore that keeps its States in the
+// named column of the provided Table
+func MakeTableStore[Key comparable, State any](
+	tbl table.Table[Key, State], col table.ColumnName,
+) (*TableStore[Key, State], error) {
+	get, err := tbl.Getter(col)
+	if err != nil {
+		return nil, err
+	}
+	set, err := tbl.Setter(col)
+	if err != nil {
+		return nil, err
+	}
+	return &TableStore[Key, State]{
+		tbl: tbl,
+		get: get,
+		set: set,
+	}, nil
+}
+
+// Get returns the State of the Key, if there is one
+func (s *TableStore[Key, State]) Get(k Key) (State, bool, error) {
+	var zero State
+	res, err := s.get(k)
+	switch {
+	case errors.Is(err, table.ErrKeyNotFound):
+		return zero, false, nil
+	case err != nil:
+		return zero, false, err
+	default:
+		return res[0], true, nil
+	}
+}
+
+// Put replaces the State of the Key
+func (s *TableStore[Key, State]) Put(k Key, st State) error {
+	return s.set(k, st)
+}
+
+// Delete removes the row of the Key, if there is one
+func (s *TableStore[Key, State]) Delete(k Key) error {
+	if err := s.tbl.Delete(k); err != nil &&
+		!errors.Is(err, table.ErrKeyNotFoundDelete) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream/node"
+	"github.com/kode4food/caravan/table"
+)
+
+func testStateStore(as *assert.Assertions, s node.StateStore[string, int]) {
+	_, ok, err := s.Get("missing")
+	as.False(ok)
+	as.Nil(err)
+
+	as.Nil(s.Put("key", 42))
+	res, ok, err := s.Get("key")
+	as.True(ok)
+	as.Nil(err)
+	as.Equal(42, res)
+
+	as.Nil(s.Delete("key"))
+	_, ok, _ = s.Get("key")
+	as.False(ok)
+	as.Nil(s.Delete("key"))
+}
+
+func TestMemoryStore(t *testing.T) {
+	as := assert.New(t)
+	s := node.MakeMemoryStore[string, int]()
+	testStateStore(as, s)
+	as.Equal(0, s.Len())
+}
+
+func TestTableStore(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := caravan.NewTable[string, int]("state")
+	as.Nil(err)
+	s, err := node.MakeTableStore(tbl, "state")
+	as.Nil(err)
+	testStateStore(as, s)
+
+	_, err = node.MakeTableStore(tbl, "missing")
+	as.ErrorIs(err, table.ErrColumnNotFound)
+}
@@ -1,11 +1,75 @@
 package node
 
 import (
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
 	"github.com/kode4food/caravan/stream"
 	"github.com/kode4food/caravan/stream/context"
 	"github.com/kode4food/caravan/table"
 )
 
+type (
+	// TableJoinMode determines how TableJoinWith handles a message whose
+	// Key isn't in the Table
+	TableJoinMode int
+
+	// TableJoinOptions configure TableJoinWith
+	TableJoinOptions[Key comparable, Value any] struct {
+		// Mode determines how a message whose Key is missing is handled
+		Mode TableJoinMode
+
+		// Default provides the values to join with a message whose Key is
+		// missing. It's required by DefaultTableJoin
+		Default func(Key) []Value
+
+		// Updates receives the Keys of the Table's rows as they change,
+		// such as the channel provided to TableWatch. If it's nil, messages
+		// are only joined as they arrive
+		Updates <-chan Key
+
+		// Retain is the number of the most recent messages of each Key
+		// that are joined again when its row changes. The default is one
+		Retain int
+
+		// TTL is how long the messages of a Key are retained once no more
+		// of them arrive. If it's zero, they're retained for as long as the
+		// Processor runs, so the number of Keys should be bounded
+		TTL time.Duration
+	}
+
+	// retainedJoins is the checkpointed state of TableJoinWith, holding the
+	// most recent messages of each Key and when the last of them arrived
+	retainedJoins[Key comparable, Msg any] struct {
+		Messages map[Key][]Msg
+		Touched  map[Key]time.Time
+	}
+)
+
+// Supported TableJoinModes
+const (
+	// AdviseTableJoin reports the missing Key as Error Advice. This is the
+	// behavior of TableJoin
+	AdviseTableJoin TableJoinMode = iota
+
+	// InnerTableJoin drops the message
+	InnerTableJoin
+
+	// LeftOuterTableJoin joins the message with a zero value for each of
+	// the columns
+	LeftOuterTableJoin
+
+	// DefaultTableJoin joins the message with the values provided by the
+	// TableJoinOptions' Default function
+	DefaultTableJoin
+)
+
+// ErrDefaultRequired is returned by TableJoinWith when DefaultTableJoin is
+// selected without a Default function
+var ErrDefaultRequired = errors.New("table join requires a default")
+
 // TableLookup performs a lookup on a table using the provided message. The Key
 // extracts a Key from this message and uses it to perform the lookup against
 // the Table. The Column returned by the lookup is forwarded to the next
@@ -116,6 +180,12 @@ func TableAggregate[Msg any, Agg any, Key comparable, Value any](
 ) stream.Processor[Msg, Agg] {
 	return func(c *context.Context[Msg, Agg]) {
 		agg := init
+		c, restored, ok := context.WithState(c, "value", func() Agg {
+			return agg
+		})
+		if ok {
+			agg = restored
+		}
 		for {
 			msg, ok := c.FetchMessage()
 			if !ok {
@@ -171,34 +241,164 @@ func TableFilter[Msg any, Key comparable, Value any](
 
 // TableJoin enriches stream messages with table data by performing a lookup
 // and combining the message with the looked-up values using the provided join
-// function
+// function. A message whose Key isn't in the Table is reported as an error
 func TableJoin[Msg any, Key comparable, Value any, Out any](
 	tbl table.Table[Key, Value], cols []table.ColumnName,
 	key table.KeySelector[Msg, Key], fn func(Msg, []Value) Out,
+) (stream.Processor[Msg, Out], error) {
+	return TableJoinWith(tbl, cols, key, fn, TableJoinOptions[Key, Value]{})
+}
+
+// TableJoinWith enriches stream messages with table data like TableJoin, but
+// handles missing Keys according to the provided TableJoinOptions. If the
+// options include Updates, the most recent messages of each Key are joined
+// again and forwarded whenever the Key's row changes, until they expire
+func TableJoinWith[Msg any, Key comparable, Value any, Out any](
+	tbl table.Table[Key, Value], cols []table.ColumnName,
+	key table.KeySelector[Msg, Key], fn func(Msg, []Value) Out,
+	opts TableJoinOptions[Key, Value],
 ) (stream.Processor[Msg, Out], error) {
 	get, err := tbl.Getter(cols...)
 	if err != nil {
 		return nil, err
 	}
+	if opts.Mode == DefaultTableJoin && opts.Default == nil {
+		return nil, ErrDefaultRequired
+	}
+	retain := max(opts.Retain, 1)
+
+	// lookup returns the values to join with the Key's messages, and
+	// whether there are any
+	lookup := func(k Key) ([]Value, bool, error) {
+		values, err := get(k)
+		switch {
+		case err == nil:
+			return values, true, nil
+		case !errors.Is(err, table.ErrKeyNotFound):
+			return nil, false, err
+		}
+		switch opts.Mode {
+		case InnerTableJoin:
+			return nil, false, nil
+		case LeftOuterTableJoin:
+			return make([]Value, len(cols)), true, nil
+		case DefaultTableJoin:
+			return opts.Default(k), true, nil
+		default:
+			return nil, false, err
+		}
+	}
 
 	return func(c *context.Context[Msg, Out]) {
-		for {
-			msg, ok := c.FetchMessage()
-			if !ok {
-				return
+		join := func(k Key, msg Msg) bool {
+			values, ok, e := lookup(k)
+			switch {
+			case e != nil:
+				return c.Error(e)
+			case !ok:
+				return true
+			default:
+				return c.ForwardResult(fn(msg, values))
 			}
+		}
 
-			k := key(msg)
-			values, e := get(k)
-			if e != nil {
-				if c.Error(e) {
-					continue
+		if opts.Updates == nil {
+			for {
+				msg, ok := c.FetchMessage()
+				if !ok || !join(key(msg), msg) {
+					return
+				}
+			}
+		}
+
+		retained := map[Key][]Msg{}
+		touched := map[Key]time.Time{}
+		c, restored, ok := context.WithState(c, "retained",
+			func() retainedJoins[Key, Msg] {
+				res := retainedJoins[Key, Msg]{
+					Messages: make(map[Key][]Msg, len(retained)),
+					Touched:  maps.Clone(touched),
 				}
+				for k, msgs := range retained {
+					res.Messages[k] = slices.Clone(msgs)
+				}
+				return res
+			},
+		)
+		if ok {
+			for k, msgs := range restored.Messages {
+				retained[k] = slices.Clone(msgs)
+			}
+			maps.Copy(touched, restored.Touched)
+		}
+
+		ttl := opts.TTL
+		expired := func(k Key, now time.Time) bool {
+			t, ok := touched[k]
+			return ttl > 0 && ok && now.Sub(t) >= ttl
+		}
+
+		swept := time.Now()
+		sweep := func(now time.Time) {
+			if ttl <= 0 || now.Sub(swept) < ttl {
 				return
 			}
+			swept = now
+			for k := range touched {
+				if expired(k, now) {
+					delete(retained, k)
+					delete(touched, k)
+				}
+			}
+		}
 
-			if !c.ForwardResult(fn(msg, values)) {
+		updates := opts.Updates
+		for {
+			select {
+			case <-c.Done:
 				return
+			case b := <-c.Barriers():
+				if !c.Checkpoint(b) {
+					return
+				}
+			case msg, ok := <-c.In:
+				if !ok {
+					return
+				}
+				c.Received(msg)
+				now := time.Now()
+				sweep(now)
+				k := key(msg)
+				msgs := append(retained[k], msg)
+				retained[k] = msgs[max(0, len(msgs)-retain):]
+				if ttl > 0 {
+					touched[k] = now
+				}
+				if !join(k, msg) {
+					return
+				}
+			case k, ok := <-updates:
+				if !ok {
+					updates = nil
+					continue
+				}
+				now := time.Now()
+				sweep(now)
+				if expired(k, now) {
+					delete(retained, k)
+					delete(touched, k)
+					continue
+				}
+				values, ok, e := lookup(k)
+				if e != nil || !ok {
+					// a deleted row has nothing to propagate
+					continue
+				}
+				for _, msg := range retained[k] {
+					if !c.ForwardResult(fn(msg, values)) {
+						return
+					}
+				}
 			}
 		}
 	}, nil
@@ -232,7 +432,8 @@ func TableDelete[Msg any, Key comparable, Value any](
 }
 
 // TableWatch emits table row data whenever the table is updated.
-// It requires a channel that signals when updates occur
+// It requires a channel that signals when updates occur. To react to every
+// change of a Table instead, consume the Topic returned by its Changes method
 func TableWatch[Key comparable, Value any](
 	tbl table.Table[Key, Value], updates <-chan Key, cols []table.ColumnName,
 ) (stream.Processor[stream.Source, []Value], error) {
@@ -246,6 +447,8 @@ func TableWatch[Key comparable, Value any](
 			select {
 			case <-c.Done:
 				return
+			case <-c.Draining():
+				return
 			case key, ok := <-updates:
 				if !ok {
 					return
@@ -568,6 +568,48 @@ func TestTableScanInterruption(t *testing.T) {
 	time.Sleep(10 * time.Millisecond)
 }
 
+func TestTableAggregateCheckpoint(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := caravan.NewTable[string, int]("count")
+	setter, _ := tbl.Setter("count")
+	aggregator := node.TableAggregate(
+		0,
+		func(count int, _ string) int {
+			return count + 1
+		},
+		func(count int) (string, []int) {
+			return "total", []int{count}
+		},
+		setter,
+	)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan string)
+	out := make(chan int)
+	barrierIn := make(chan *context.Barrier)
+	barrierOut := make(chan *context.Barrier)
+
+	c := context.Make(done, make(chan context.Advice), in, out)
+	c = context.WithBarriers(c, barrierIn, barrierOut)
+	c = context.WithRestored(c, map[string]any{"/value": 5})
+	aggregator.Start(c)
+
+	in <- "msg"
+	as.Equal(6, <-out)
+
+	getter, _ := tbl.Getter("count")
+	val, err := getter("total")
+	as.Nil(err)
+	as.Equal([]int{6}, val)
+
+	b := context.MakeBarrier(1)
+	barrierIn <- b
+	as.Equal(b, <-barrierOut)
+	as.Equal(6, b.State()["/value"])
+}
+
 func TestTableAggregateWithError(t *testing.T) {
 	as := assert.New(t)
 
@@ -754,3 +796,154 @@ func TestTableDeleteWithError(t *testing.T) {
 
 	close(done)
 }
+
+func makeTableJoin(
+	tbl table.Table[string, string], opts node.TableJoinOptions[string, string],
+) (stream.Processor[string, string], error) {
+	return node.TableJoinWith(
+		tbl,
+		[]table.ColumnName{"name", "value"},
+		func(id string) string { return id },
+		func(id string, vals []string) string {
+			return id + ":" + vals[0] + ":" + vals[1]
+		},
+		opts,
+	)
+}
+
+func TestTableJoinModes(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, updater := makeTestTable()
+	as.Nil(updater.Update(&row{id: "id1", name: "name1", value: "value1"}))
+
+	for _, tc := range []struct {
+		opts node.TableJoinOptions[string, string]
+		res  string
+	}{
+		{
+			opts: node.TableJoinOptions[string, string]{
+				Mode: node.InnerTableJoin,
+			},
+		},
+		{
+			opts: node.TableJoinOptions[string, string]{
+				Mode: node.LeftOuterTableJoin,
+			},
+			res: "missing::",
+		},
+		{
+			opts: node.TableJoinOptions[string, string]{
+				Mode: node.DefaultTableJoin,
+				Default: func(id string) []string {
+					return []string{"unknown", id}
+				},
+			},
+			res: "missing:unknown:missing",
+		},
+	} {
+		joiner, err := makeTableJoin(tbl, tc.opts)
+		as.Nil(err)
+
+		done := make(chan context.Done)
+		in := make(chan string)
+		out := make(chan string)
+		joiner.Start(context.Make(done, make(chan context.Advice), in, out))
+
+		in <- "missing"
+		if tc.res != "" {
+			as.Equal(tc.res, <-out)
+		}
+		in <- "id1"
+		as.Equal("id1:name1:value1", <-out)
+		close(done)
+	}
+}
+
+func TestTableJoinDefaultRequired(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := makeTestTable()
+	joiner, err := makeTableJoin(tbl, node.TableJoinOptions[string, string]{
+		Mode: node.DefaultTableJoin,
+	})
+	as.Nil(joiner)
+	as.ErrorIs(err, node.ErrDefaultRequired)
+}
+
+func TestTableJoinUpdates(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, updater := makeTestTable()
+	as.Nil(updater.Update(&row{id: "id1", name: "name1", value: "value1"}))
+	updates := make(chan string)
+
+	joiner, err := makeTableJoin(tbl, node.TableJoinOptions[string, string]{
+		Mode:    node.InnerTableJoin,
+		Updates: updates,
+		Retain:  2,
+	})
+	as.Nil(err)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan string)
+	out := make(chan string)
+	joiner.Start(context.Make(done, make(chan context.Advice), in, out))
+
+	in <- "id1"
+	as.Equal("id1:name1:value1", <-out)
+	in <- "id2"
+
+	// the retained message of a Key is joined again when its row changes
+	as.Nil(updater.Update(&row{id: "id2", name: "name2", value: "value2"}))
+	updates <- "id2"
+	as.Equal("id2:name2:value2", <-out)
+
+	as.Nil(updater.Update(&row{id: "id1", name: "name3", value: "value3"}))
+	updates <- "id1"
+	as.Equal("id1:name3:value3", <-out)
+
+	// a deleted row has nothing to propagate
+	as.Nil(tbl.Delete("id1"))
+	updates <- "id1"
+	in <- "id2"
+	as.Equal("id2:name2:value2", <-out)
+}
+
+func TestTableJoinTTL(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, updater := makeTestTable()
+	as.Nil(updater.Update(&row{id: "id1", name: "name1", value: "value1"}))
+	updates := make(chan string)
+
+	joiner, err := makeTableJoin(tbl, node.TableJoinOptions[string, string]{
+		Updates: updates,
+		TTL:     20 * time.Millisecond,
+	})
+	as.Nil(err)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan string)
+	out := make(chan string)
+	joiner.Start(context.Make(done, make(chan context.Advice), in, out))
+
+	in <- "id1"
+	as.Equal("id1:name1:value1", <-out)
+	updates <- "id1"
+	as.Equal("id1:name1:value1", <-out)
+
+	// the retained message has expired, so it isn't joined again
+	time.Sleep(30 * time.Millisecond)
+	as.Nil(updater.Update(&row{id: "id1", name: "name2", value: "value2"}))
+	updates <- "id1"
+	select {
+	case <-out:
+		as.Fail("expired message should not be joined again")
+	case <-time.After(10 * time.Millisecond):
+	}
+	in <- "id1"
+	as.Equal("id1:name2:value2", <-out)
+}
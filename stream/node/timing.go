@@ -29,44 +29,46 @@ func Throttle[Msg any](rate time.Duration) stream.Processor[Msg, Msg] {
 }
 
 // Debounce constructs a Processor that emits a message only after the
-// specified duration has passed with no new messages arriving
+// specified duration has passed with no new messages arriving. A pending
+// message is flushed if the input is closed
 func Debounce[Msg any](d time.Duration) stream.Processor[Msg, Msg] {
 	return func(c *context.Context[Msg, Msg]) {
-		var timer *time.Timer
-		pending := make(chan Msg, 1)
-		done := make(chan struct{})
-		defer close(done)
+		timer := time.NewTimer(d)
+		timer.Stop()
+		defer timer.Stop()
 
-		go func() {
-			for {
-				select {
-				case <-done:
+		var pending *Msg
+		c, pending, _ = context.WithState(c, "pending", func() *Msg {
+			return pending
+		})
+		if pending != nil {
+			timer.Reset(d)
+		}
+
+		for {
+			select {
+			case <-c.Done:
+				return
+			case b := <-c.Barriers():
+				if !c.Checkpoint(b) {
 					return
-				case msg := <-pending:
-					if timer != nil {
-						timer.Stop()
+				}
+			case msg, ok := <-c.In:
+				if !ok {
+					if pending != nil {
+						c.ForwardResult(*pending)
 					}
-					timer = time.AfterFunc(d, func() {
-						c.ForwardResult(msg)
-					})
+					return
 				}
-			}
-		}()
-
-		for {
-			msg, ok := c.FetchMessage()
-			if !ok {
-				if timer != nil {
-					timer.Stop()
+				c.Received(msg)
+				pending = &msg
+				timer.Reset(d)
+			case <-timer.C:
+				msg := *pending
+				pending = nil
+				if !c.ForwardResult(msg) {
+					return
 				}
-				return
-			}
-
-			select {
-			case pending <- msg:
-			default:
-				<-pending
-				pending <- msg
 			}
 		}
 	}
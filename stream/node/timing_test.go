@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream/context"
 	"github.com/kode4food/caravan/stream/node"
 )
 
@@ -255,3 +256,21 @@ func TestSample(t *testing.T) {
 		}
 	}
 }
+
+func TestDebounceFlushOnClose(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan context.Done)
+	defer close(done)
+	in := make(chan int)
+	out := make(chan int)
+
+	node.Debounce[int](time.Minute).Start(
+		context.Make(done, make(chan context.Advice), in, out),
+	)
+
+	in <- 1
+	in <- 2
+	close(in)
+	as.Equal(2, <-out)
+}
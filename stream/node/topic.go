@@ -1,23 +1,118 @@
 package node
 
 import (
+	"sync/atomic"
+
 	"github.com/kode4food/caravan/topic"
 
 	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
 )
 
 // TopicConsumer constructs a processor that receives from the provided Topic
-// every time it's invoked by the Stream
+// every time it's invoked by the Stream. If the Stream is checkpointing, the
+// offset of the next message to be received is checkpointed, and each run of
+// the processor consumes from the restored offset, or from the earliest
+// message the Topic retains if there is none. Otherwise, each run continues
+// from where the previous one left off
 func TopicConsumer[Msg any](
 	t topic.Topic[Msg],
 ) stream.Processor[stream.Source, Msg] {
-	ch := t.NewConsumer().Receive()
-	return GenerateFrom(ch)
+	var cursor atomic.Uint64
+	return func(c *context.Context[stream.Source, Msg]) {
+		var next uint64
+		if c.Barriers() == nil {
+			next = cursor.Load()
+		}
+		c, restored, ok := context.WithState(c, "offset", func() uint64 {
+			return next
+		})
+		if ok {
+			next = restored
+		}
+		cons := t.NewConsumerFrom(next)
+		defer cons.Close()
+
+		for {
+			if _, ok := c.FetchMessage(); !ok {
+				return
+			}
+			e, ok := receiveEntry(c, cons)
+			if !ok || !c.ForwardResult(e.Message) {
+				return
+			}
+			next = e.Offset + 1
+			cursor.Store(next)
+		}
+	}
+}
+
+// receiveEntry waits for the Consumer's next Entry, checkpointing any Barriers
+// that arrive in the meantime
+func receiveEntry[Msg any](
+	c *context.Context[stream.Source, Msg], cons topic.Consumer[topic.Entry[Msg]],
+) (topic.Entry[Msg], bool) {
+	var zero topic.Entry[Msg]
+	for {
+		select {
+		case <-c.Done:
+			return zero, false
+		case <-c.Draining():
+			return zero, false
+		case b := <-c.Barriers():
+			if !c.Checkpoint(b) {
+				return zero, false
+			}
+		case e, ok := <-cons.Receive():
+			return e, ok
+		}
+	}
 }
 
 // TopicProducer constructs a processor that sends all messages it sees to the
-// provided Topic
+// provided Topic, and then forwards them. If the Stream is checkpointing, the
+// messages are held back until the checkpoint that follows them has been
+// saved, so that those replayed after the Stream is restored aren't sent to
+// the Topic a second time
 func TopicProducer[Msg any](t topic.Topic[Msg]) stream.Processor[Msg, Msg] {
 	ch := t.NewProducer().Send()
-	return SidechainTo(ch)
+	p := SidechainTo(ch)
+	return func(c *context.Context[Msg, Msg]) {
+		if c.Barriers() == nil {
+			p(c)
+			return
+		}
+
+		var held []Msg
+		for {
+			msg, b, ok := c.Receive()
+			switch {
+			case !ok:
+				if f := c.Final(); f != nil && !c.IsDone() {
+					f.OnCommit(sendAll(ch, held))
+				}
+				return
+			case b != nil:
+				b.OnCommit(sendAll(ch, held))
+				held = nil
+				if !c.Checkpoint(b) {
+					return
+				}
+			default:
+				held = append(held, msg)
+				if !c.ForwardResult(msg) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// sendAll returns a function that sends the provided messages to a channel
+func sendAll[Msg any](ch chan<- Msg, msgs []Msg) func() {
+	return func() {
+		for _, msg := range msgs {
+			ch <- msg
+		}
+	}
 }
@@ -0,0 +1,37 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+func TestTopicConsumerResumes(t *testing.T) {
+	as := assert.New(t)
+
+	in := caravan.NewTopic[int]()
+	p := in.NewProducer()
+	defer p.Close()
+	out := make(chan int)
+
+	s := caravan.NewStream(
+		node.TopicConsumer(in),
+		node.SidechainTo(out),
+	)
+
+	running := s.Start()
+	for i := range 3 {
+		p.Send() <- i
+		as.Equal(i, <-out)
+	}
+	as.Nil(running.Stop())
+	as.Nil(running.Wait())
+
+	running = s.Start()
+	defer func() { _ = running.Stop() }()
+	p.Send() <- 3
+	as.Equal(3, <-out)
+}
@@ -13,6 +13,10 @@ type (
 	// message is forwarded downstream
 	Mapper[From, To any] func(From) To
 
+	// ErrMapper transforms a message from one type to another, or fails
+	// with an error. Only successful results are forwarded downstream
+	ErrMapper[From, To any] func(From) (To, error)
+
 	// FlatMapper transforms one message into zero or more messages. Each
 	// result is forwarded downstream in order
 	FlatMapper[From, To any] func(From) []To
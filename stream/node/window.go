@@ -1,12 +1,32 @@
 package node
 
 import (
+	"slices"
 	"time"
 
 	"github.com/kode4food/caravan/stream"
 	"github.com/kode4food/caravan/stream/context"
 )
 
+type (
+	// WindowResult is a window of messages along with its metadata. For the
+	// count windows, Start and End are the times the window's first and
+	// last messages arrived. For the time windows, they're the bounds of
+	// the period the window covers
+	WindowResult[Msg any] struct {
+		Start    time.Time
+		End      time.Time
+		Count    int
+		Messages []Msg
+	}
+
+	// arrival is a message along with the time it arrived
+	arrival[Msg any] struct {
+		At  time.Time
+		Msg Msg
+	}
+)
+
 // Window constructs a Processor that collects messages into time-based
 // windows. Emits a batch every duration, regardless of size
 func Window[Msg any](duration time.Duration) stream.Processor[Msg, []Msg] {
@@ -15,6 +35,12 @@ func Window[Msg any](duration time.Duration) stream.Processor[Msg, []Msg] {
 		defer ticker.Stop()
 
 		window := make([]Msg, 0)
+		c, restored, ok := context.WithState(c, "window", func() []Msg {
+			return slices.Clone(window)
+		})
+		if ok {
+			window = append(window, restored...)
+		}
 
 		for {
 			select {
@@ -41,10 +67,17 @@ func Window[Msg any](duration time.Duration) stream.Processor[Msg, []Msg] {
 }
 
 // SlidingWindow constructs a Processor that collects messages into sliding
-// windows of a fixed size. Emits a new window after each message
+// windows of a fixed size. Emits a new window after each message, once the
+// window is full. Each window is a copy that the Processor doesn't retain
 func SlidingWindow[Msg any](size int) stream.Processor[Msg, []Msg] {
 	return func(c *context.Context[Msg, []Msg]) {
 		window := make([]Msg, 0, size)
+		c, restored, ok := context.WithState(c, "window", func() []Msg {
+			return slices.Clone(window)
+		})
+		if ok {
+			window = append(window, restored...)
+		}
 
 		for {
 			msg, ok := c.FetchMessage()
@@ -56,9 +89,187 @@ func SlidingWindow[Msg any](size int) stream.Processor[Msg, []Msg] {
 			if len(window) > size {
 				window = window[1:]
 			}
-			if len(window) == size && !c.ForwardResult(window) {
+			if len(window) < size {
+				continue
+			}
+			if !c.ForwardResult(slices.Clone(window)) {
+				return
+			}
+		}
+	}
+}
+
+// TumblingCountWindow constructs a Processor that collects messages into
+// consecutive windows of a fixed size. Emits a window each time it's full,
+// and flushes the last window on close, even if it isn't full
+func TumblingCountWindow[Msg any](
+	size int,
+) stream.Processor[Msg, WindowResult[Msg]] {
+	return func(c *context.Context[Msg, WindowResult[Msg]]) {
+		window := make([]arrival[Msg], 0, size)
+		c, restored, ok := context.WithState(c, "window",
+			func() []arrival[Msg] {
+				return slices.Clone(window)
+			},
+		)
+		if ok {
+			window = append(window, restored...)
+		}
+
+		for {
+			msg, ok := c.FetchMessage()
+			if !ok {
+				// Flush remaining window on close
+				if len(window) > 0 {
+					c.ForwardResult(countWindow(window))
+				}
+				return
+			}
+
+			window = append(window, arrival[Msg]{At: time.Now(), Msg: msg})
+			if len(window) < size {
+				continue
+			}
+			if !c.ForwardResult(countWindow(window)) {
+				return
+			}
+			window = make([]arrival[Msg], 0, size)
+		}
+	}
+}
+
+// SlidingCountWindow constructs a Processor that collects messages into
+// sliding windows of a fixed size. Once the first window is full, emits a
+// window of the most recent messages each time another slide messages have
+// arrived
+func SlidingCountWindow[Msg any](
+	size, slide int,
+) stream.Processor[Msg, WindowResult[Msg]] {
+	type state struct {
+		Window []arrival[Msg]
+		Since  int
+	}
+
+	return func(c *context.Context[Msg, WindowResult[Msg]]) {
+		var st state
+		c, restored, ok := context.WithState(c, "window", func() state {
+			return state{
+				Window: slices.Clone(st.Window),
+				Since:  st.Since,
+			}
+		})
+		if ok {
+			st = state{
+				Window: slices.Clone(restored.Window),
+				Since:  restored.Since,
+			}
+		}
+
+		for {
+			msg, ok := c.FetchMessage()
+			if !ok {
+				return
+			}
+
+			st.Window = append(st.Window, arrival[Msg]{
+				At:  time.Now(),
+				Msg: msg,
+			})
+			if len(st.Window) > size {
+				st.Window = slices.Delete(st.Window, 0, len(st.Window)-size)
+			}
+			st.Since++
+			if len(st.Window) < size || st.Since < slide {
+				continue
+			}
+			st.Since = 0
+			if !c.ForwardResult(countWindow(st.Window)) {
+				return
+			}
+		}
+	}
+}
+
+// SlidingTimeWindow constructs a Processor that collects messages into
+// sliding windows covering a fixed period. Every slide, emits a window of the
+// messages that arrived within the period before it, unless there are none.
+// The last window is flushed on close
+func SlidingTimeWindow[Msg any](
+	size, slide time.Duration,
+) stream.Processor[Msg, WindowResult[Msg]] {
+	return func(c *context.Context[Msg, WindowResult[Msg]]) {
+		ticker := time.NewTicker(slide)
+		defer ticker.Stop()
+
+		var window []arrival[Msg]
+		c, restored, ok := context.WithState(c, "window",
+			func() []arrival[Msg] {
+				return slices.Clone(window)
+			},
+		)
+		if ok {
+			window = slices.Clone(restored)
+		}
+
+		current := func(end time.Time) WindowResult[Msg] {
+			start := end.Add(-size)
+			window = slices.DeleteFunc(window, func(a arrival[Msg]) bool {
+				return !a.At.After(start)
+			})
+			res := WindowResult[Msg]{
+				Start:    start,
+				End:      end,
+				Count:    len(window),
+				Messages: make([]Msg, len(window)),
+			}
+			for i, a := range window {
+				res.Messages[i] = a.Msg
+			}
+			return res
+		}
+
+		for {
+			select {
+			case <-c.Done:
 				return
+			case b := <-c.Barriers():
+				if !c.Checkpoint(b) {
+					return
+				}
+			case msg, ok := <-c.In:
+				if !ok {
+					// Flush remaining window on close
+					if res := current(time.Now()); res.Count > 0 {
+						c.ForwardResult(res)
+					}
+					return
+				}
+				c.Received(msg)
+				window = append(window, arrival[Msg]{
+					At:  time.Now(),
+					Msg: msg,
+				})
+			case now := <-ticker.C:
+				if res := current(now); res.Count > 0 {
+					if !c.ForwardResult(res) {
+						return
+					}
+				}
 			}
 		}
 	}
 }
+
+// countWindow copies the messages of a count window into a WindowResult
+func countWindow[Msg any](window []arrival[Msg]) WindowResult[Msg] {
+	res := WindowResult[Msg]{
+		Start:    window[0].At,
+		End:      window[len(window)-1].At,
+		Count:    len(window),
+		Messages: make([]Msg, len(window)),
+	}
+	for i, a := range window {
+		res.Messages[i] = a.Msg
+	}
+	return res
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/kode4food/caravan"
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
 	"github.com/kode4food/caravan/stream/node"
 )
 
@@ -84,3 +86,99 @@ func TestSlidingWindow(t *testing.T) {
 	window3 := <-c.Receive()
 	assert.Equal(t, []int{2, 3, 4}, window3)
 }
+
+func startWindow[Out any](
+	p stream.Processor[int, Out],
+) (chan int, chan Out, func()) {
+	done := make(chan context.Done)
+	in := make(chan int)
+	out := make(chan Out)
+	p.Start(context.Make(done, make(chan context.Advice), in, out))
+	return in, out, func() { close(done) }
+}
+
+func TestSlidingWindowCopies(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startWindow(node.SlidingWindow[int](2))
+	defer stop()
+
+	var windows [][]int
+	in <- 0
+	for i := 1; i < 5; i++ {
+		in <- i
+		windows = append(windows, <-out)
+	}
+	as.Equal([][]int{{0, 1}, {1, 2}, {2, 3}, {3, 4}}, windows)
+}
+
+func TestTumblingCountWindow(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startWindow(node.TumblingCountWindow[int](2))
+	defer stop()
+
+	before := time.Now()
+	in <- 1
+	in <- 2
+	w := <-out
+	as.Equal([]int{1, 2}, w.Messages)
+	as.Equal(2, w.Count)
+	as.False(w.Start.Before(before))
+	as.False(w.End.Before(w.Start))
+
+	in <- 3
+	in <- 4
+	as.Equal([]int{3, 4}, (<-out).Messages)
+
+	in <- 5
+	close(in)
+	w = <-out
+	as.Equal([]int{5}, w.Messages)
+	as.Equal(1, w.Count)
+}
+
+func TestSlidingCountWindow(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startWindow(node.SlidingCountWindow[int](3, 2))
+	defer stop()
+
+	var windows []node.WindowResult[int]
+	go func() {
+		for i := range 7 {
+			in <- i
+		}
+	}()
+	for range 3 {
+		windows = append(windows, <-out)
+	}
+	as.Equal([]int{0, 1, 2}, windows[0].Messages)
+	as.Equal([]int{2, 3, 4}, windows[1].Messages)
+	as.Equal([]int{4, 5, 6}, windows[2].Messages)
+	as.Equal(3, windows[2].Count)
+}
+
+func TestSlidingTimeWindow(t *testing.T) {
+	as := assert.New(t)
+
+	in, out, stop := startWindow(node.SlidingTimeWindow[int](
+		150*time.Millisecond, 50*time.Millisecond,
+	))
+	defer stop()
+
+	in <- 1
+	w := <-out
+	as.Equal([]int{1}, w.Messages)
+	as.Equal(150*time.Millisecond, w.End.Sub(w.Start))
+
+	go func() { in <- 2 }()
+	// the message stays in the windows that follow until it's too old
+	for {
+		w = <-out
+		if len(w.Messages) == 1 && w.Messages[0] == 2 {
+			break
+		}
+		as.Contains(w.Messages, 1)
+	}
+}
@@ -0,0 +1,318 @@
+package node
+
+import (
+	"time"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+)
+
+type (
+	// JoinMode determines which messages a join forwards when they aren't
+	// matched by a message from the other side
+	JoinMode int
+
+	// JoinWindow configures a WindowJoin
+	JoinWindow struct {
+		// Size is how far apart, in arrival time, a message from each side
+		// may be and still be matched
+		Size time.Duration
+
+		// Mode selects which unmatched messages are forwarded once they
+		// expire
+		Mode JoinMode
+
+		// MaxPerKey limits the number of messages kept for each Key on each
+		// side. Once it's reached, the oldest message of the Key expires
+		// early to make room. Zero means no limit
+		MaxPerKey int
+	}
+
+	// Joined is the result of a WindowJoin. For outer joins, one of its
+	// sides may be missing, as reported by HasLeft and HasRight, in which
+	// case that side is a zero value
+	Joined[Left, Right any] struct {
+		Left     Left
+		Right    Right
+		HasLeft  bool
+		HasRight bool
+	}
+
+	// joinEntry is a message kept by one side of a WindowJoin
+	joinEntry[Key comparable, Msg any] struct {
+		Key     Key
+		Msg     Msg
+		At      time.Time
+		Matched bool
+		expired bool
+	}
+
+	// joinSide keeps the messages of one side of a WindowJoin in the order
+	// they arrived, and by Key
+	joinSide[Key comparable, Msg any] struct {
+		queue []*joinEntry[Key, Msg]
+		byKey map[Key][]*joinEntry[Key, Msg]
+	}
+
+	// joinState is the checkpointed state of a WindowJoin
+	joinState[Key comparable, Left, Right any] struct {
+		Lefts  []joinEntry[Key, Left]
+		Rights []joinEntry[Key, Right]
+	}
+)
+
+// Supported JoinModes
+const (
+	// InnerJoin only forwards matched messages
+	InnerJoin JoinMode = iota
+
+	// LeftOuterJoin also forwards the left messages that expire unmatched
+	LeftOuterJoin
+
+	// FullOuterJoin also forwards the messages of either side that expire
+	// unmatched
+	FullOuterJoin
+)
+
+// WindowJoin constructs a Processor that joins the messages of two
+// Processors by Key. Each message is kept for the JoinWindow's Size after it
+// arrives, and is matched with every message of the same Key that arrives
+// from the other side in the meantime. Each match is forwarded as soon as
+// it's made. Messages that expire unmatched are forwarded according to the
+// JoinWindow's Mode, as are those that remain when both Processors complete
+func WindowJoin[Left, Right any, Key comparable](
+	left stream.Processor[stream.Source, Left],
+	right stream.Processor[stream.Source, Right],
+	leftKey KeySelector[Left, Key], rightKey KeySelector[Right, Key],
+	w JoinWindow,
+) stream.Processor[stream.Source, Joined[Left, Right]] {
+	return stream.Composite(func(
+		c *context.Context[stream.Source, Joined[Left, Right]],
+	) {
+		leftOut, rightOut, c, stop := startBinaryProcessors(c, left, right)
+		defer stop()
+		if c.Describing() {
+			return
+		}
+
+		lefts := makeJoinSide[Key, Left]()
+		rights := makeJoinSide[Key, Right]()
+		c, restored, ok := context.WithState(c, "join",
+			func() joinState[Key, Left, Right] {
+				return joinState[Key, Left, Right]{
+					Lefts:  lefts.entries(),
+					Rights: rights.entries(),
+				}
+			},
+		)
+		if ok {
+			lefts.restore(restored.Lefts)
+			rights.restore(restored.Rights)
+		}
+
+		timer := time.NewTimer(w.Size)
+		defer timer.Stop()
+		resetTimer := func(now time.Time) {
+			next := earliest(lefts.next(), rights.next())
+			if next.IsZero() {
+				timer.Stop()
+				return
+			}
+			timer.Reset(max(0, next.Add(w.Size).Sub(now)))
+		}
+		resetTimer(time.Now())
+
+		unmatchedLeft := func(e *joinEntry[Key, Left]) bool {
+			if e.Matched || w.Mode == InnerJoin {
+				return true
+			}
+			return c.ForwardResult(Joined[Left, Right]{
+				Left:    e.Msg,
+				HasLeft: true,
+			})
+		}
+
+		unmatchedRight := func(e *joinEntry[Key, Right]) bool {
+			if e.Matched || w.Mode != FullOuterJoin {
+				return true
+			}
+			return c.ForwardResult(Joined[Left, Right]{
+				Right:    e.Msg,
+				HasRight: true,
+			})
+		}
+
+		expire := func(before time.Time) bool {
+			for _, e := range lefts.expire(before) {
+				if !unmatchedLeft(e) {
+					return false
+				}
+			}
+			for _, e := range rights.expire(before) {
+				if !unmatchedRight(e) {
+					return false
+				}
+			}
+			return true
+		}
+
+		for leftOut != nil || rightOut != nil {
+			select {
+			case <-c.Done:
+				return
+			case b := <-c.Barriers():
+				if !c.Checkpoint(b) {
+					return
+				}
+			case now := <-timer.C:
+				if !expire(now.Add(-w.Size)) {
+					return
+				}
+				resetTimer(now)
+			case msg, ok := <-leftOut:
+				if !ok {
+					leftOut = nil
+					continue
+				}
+				now := time.Now()
+				e := &joinEntry[Key, Left]{
+					Key: leftKey(msg), Msg: msg, At: now,
+				}
+				for _, r := range rights.byKey[e.Key] {
+					if now.Sub(r.At) > w.Size {
+						continue
+					}
+					e.Matched, r.Matched = true, true
+					if !c.ForwardResult(Joined[Left, Right]{
+						Left:     msg,
+						Right:    r.Msg,
+						HasLeft:  true,
+						HasRight: true,
+					}) {
+						return
+					}
+				}
+				if old := lefts.add(e, w.MaxPerKey); old != nil {
+					if !unmatchedLeft(old) {
+						return
+					}
+				}
+				resetTimer(now)
+			case msg, ok := <-rightOut:
+				if !ok {
+					rightOut = nil
+					continue
+				}
+				now := time.Now()
+				e := &joinEntry[Key, Right]{
+					Key: rightKey(msg), Msg: msg, At: now,
+				}
+				for _, l := range lefts.byKey[e.Key] {
+					if now.Sub(l.At) > w.Size {
+						continue
+					}
+					e.Matched, l.Matched = true, true
+					if !c.ForwardResult(Joined[Left, Right]{
+						Left:     l.Msg,
+						Right:    msg,
+						HasLeft:  true,
+						HasRight: true,
+					}) {
+						return
+					}
+				}
+				if old := rights.add(e, w.MaxPerKey); old != nil {
+					if !unmatchedRight(old) {
+						return
+					}
+				}
+				resetTimer(now)
+			}
+		}
+
+		// Flush the unmatched messages once both sides have completed
+		expire(maxTime)
+	})
+}
+
+func makeJoinSide[Key comparable, Msg any]() *joinSide[Key, Msg] {
+	return &joinSide[Key, Msg]{
+		byKey: map[Key][]*joinEntry[Key, Msg]{},
+	}
+}
+
+// add keeps the entry, returning the entry it displaced, if any, when the
+// entry's Key has reached the provided limit
+func (s *joinSide[Key, Msg]) add(
+	e *joinEntry[Key, Msg], limit int,
+) *joinEntry[Key, Msg] {
+	s.queue = append(s.queue, e)
+	entries := append(s.byKey[e.Key], e)
+	s.byKey[e.Key] = entries
+	if limit <= 0 || len(entries) <= limit {
+		return nil
+	}
+	res := entries[0]
+	res.expired = true
+	s.byKey[e.Key] = entries[1:]
+	return res
+}
+
+// expire removes and returns the entries that arrived before the provided
+// time, in the order they arrived
+func (s *joinSide[Key, Msg]) expire(before time.Time) []*joinEntry[Key, Msg] {
+	var res []*joinEntry[Key, Msg]
+	for len(s.queue) > 0 {
+		e := s.queue[0]
+		if !e.expired && !e.At.Before(before) {
+			break
+		}
+		s.queue = s.queue[1:]
+		if e.expired {
+			continue
+		}
+		e.expired = true
+		if entries := s.byKey[e.Key][1:]; len(entries) > 0 {
+			s.byKey[e.Key] = entries
+		} else {
+			delete(s.byKey, e.Key)
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+// next returns the arrival time of the oldest entry that hasn't expired, or
+// the zero time if there isn't one
+func (s *joinSide[Key, Msg]) next() time.Time {
+	for _, e := range s.queue {
+		if !e.expired {
+			return e.At
+		}
+	}
+	return time.Time{}
+}
+
+func (s *joinSide[Key, Msg]) entries() []joinEntry[Key, Msg] {
+	res := make([]joinEntry[Key, Msg], 0, len(s.queue))
+	for _, e := range s.queue {
+		if !e.expired {
+			res = append(res, *e)
+		}
+	}
+	return res
+}
+
+func (s *joinSide[Key, Msg]) restore(entries []joinEntry[Key, Msg]) {
+	for _, e := range entries {
+		s.add(&e, 0)
+	}
+}
+
+// earliest returns the earlier of two times, ignoring zero times
+func earliest(l, r time.Time) time.Time {
+	if l.IsZero() || !r.IsZero() && r.Before(l) {
+		return r
+	}
+	return l
+}
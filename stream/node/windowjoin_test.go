@@ -0,0 +1,152 @@
+package node_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/node"
+)
+
+type (
+	order struct {
+		id    string
+		total int
+	}
+
+	payment struct {
+		order  string
+		amount int
+	}
+
+	orderPayment = node.Joined[order, payment]
+)
+
+func chanSource[Msg any](ch <-chan Msg) stream.Processor[stream.Source, Msg] {
+	return func(c *context.Context[stream.Source, Msg]) {
+		for msg := range ch {
+			if !c.ForwardResult(msg) {
+				return
+			}
+		}
+	}
+}
+
+func startWindowJoin(w node.JoinWindow) (
+	chan order, chan payment, chan orderPayment, chan context.Done, func(),
+) {
+	done := make(chan context.Done)
+	orders := make(chan order)
+	payments := make(chan payment)
+	out := make(chan orderPayment)
+	finished := make(chan context.Done)
+	node.WindowJoin(
+		chanSource(orders), chanSource(payments),
+		func(o order) string { return o.id },
+		func(p payment) string { return p.order },
+		w,
+	).Start(context.WithCompletion(
+		context.Make[stream.Source](
+			done, make(chan context.Advice), nil, out,
+		),
+		func() { close(finished) },
+	))
+	return orders, payments, out, finished, func() { close(done) }
+}
+
+func TestWindowJoin(t *testing.T) {
+	as := assert.New(t)
+
+	orders, payments, out, _, stop := startWindowJoin(node.JoinWindow{
+		Size: time.Second,
+	})
+	defer stop()
+
+	orders <- order{id: "a", total: 10}
+	orders <- order{id: "b", total: 20}
+	payments <- payment{order: "b", amount: 20}
+	as.Equal(orderPayment{
+		Left:     order{id: "b", total: 20},
+		Right:    payment{order: "b", amount: 20},
+		HasLeft:  true,
+		HasRight: true,
+	}, <-out)
+
+	// both payments match the same order
+	payments <- payment{order: "a", amount: 4}
+	as.Equal(4, (<-out).Right.amount)
+	payments <- payment{order: "a", amount: 6}
+	as.Equal(6, (<-out).Right.amount)
+}
+
+func TestWindowJoinExpiry(t *testing.T) {
+	as := assert.New(t)
+
+	orders, payments, out, _, stop := startWindowJoin(node.JoinWindow{
+		Size: 50 * time.Millisecond,
+		Mode: node.LeftOuterJoin,
+	})
+	defer stop()
+
+	orders <- order{id: "a", total: 10}
+	payments <- payment{order: "z", amount: 1}
+	as.Equal(orderPayment{
+		Left:    order{id: "a", total: 10},
+		HasLeft: true,
+	}, <-out)
+
+	// the order has expired, so the payment isn't matched
+	payments <- payment{order: "a", amount: 10}
+	orders <- order{id: "b", total: 5}
+	as.Equal("b", (<-out).Left.id)
+}
+
+func TestWindowJoinFullOuter(t *testing.T) {
+	as := assert.New(t)
+
+	orders, payments, out, finished, stop := startWindowJoin(
+		node.JoinWindow{
+			Size: time.Minute,
+			Mode: node.FullOuterJoin,
+		},
+	)
+	defer stop()
+
+	orders <- order{id: "a", total: 10}
+	payments <- payment{order: "a", amount: 10}
+	as.True((<-out).HasRight)
+	payments <- payment{order: "b", amount: 5}
+
+	// the unmatched payment is flushed once both sides complete
+	close(orders)
+	close(payments)
+	as.Equal(orderPayment{
+		Right:    payment{order: "b", amount: 5},
+		HasRight: true,
+	}, <-out)
+	<-finished
+}
+
+func TestWindowJoinMaxPerKey(t *testing.T) {
+	as := assert.New(t)
+
+	orders, payments, out, _, stop := startWindowJoin(node.JoinWindow{
+		Size:      time.Minute,
+		Mode:      node.LeftOuterJoin,
+		MaxPerKey: 1,
+	})
+	defer stop()
+
+	orders <- order{id: "a", total: 1}
+	orders <- order{id: "a", total: 2}
+	as.Equal(orderPayment{
+		Left:    order{id: "a", total: 1},
+		HasLeft: true,
+	}, <-out)
+
+	payments <- payment{order: "a", amount: 2}
+	as.Equal(2, (<-out).Left.total)
+}
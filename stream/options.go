@@ -0,0 +1,120 @@
+package stream
+
+import (
+	"log/slog"
+
+	"github.com/kode4food/caravan/stream/metrics"
+	"github.com/kode4food/caravan/stream/trace"
+)
+
+type (
+	// Options configure the features of a Stream that cut across all of its
+	// Processors. The zero value is a Stream with none of them enabled
+	Options struct {
+		// Name identifies the Stream in the records it logs
+		Name string
+
+		// Logger receives the Stream's log records. If nil, the default
+		// Logger is used
+		Logger *slog.Logger
+
+		// AdviceHandler is given first crack at the Advice being received
+		// by the Stream whenever it's started with Start
+		AdviceHandler AdviceHandler
+
+		// Supervision determines how the Stream responds to a panicking
+		// Processor
+		Supervision Supervision
+
+		// Checkpointing determines how often the Stream checkpoints the
+		// state of its Processors, and where it's kept
+		Checkpointing Checkpointing
+
+		// Metrics receives the metrics recorded by the Stream's Processors
+		Metrics metrics.Sink
+
+		// Tracer starts a Span for each message handled by the Stream's
+		// Processors
+		Tracer trace.Tracer
+
+		// Buffer is the number of messages held by the channels between the
+		// Stream's Processors. Zero means they're unbuffered
+		Buffer int
+	}
+
+	// Option sets one of a Stream's Options
+	Option func(*Options)
+)
+
+// Apply returns a copy of the Options with the provided Options applied
+func (o Options) Apply(opts ...Option) Options {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithName identifies the Stream by name in the records it logs
+func WithName(name string) Option {
+	return func(o *Options) {
+		o.Name = name
+	}
+}
+
+// WithLogger has the Stream log to the provided Logger rather than the
+// default
+func WithLogger(l *slog.Logger) Option {
+	return func(o *Options) {
+		o.Logger = l
+	}
+}
+
+// WithAdviceHandler gives the provided AdviceHandler first crack at the
+// Advice received by the Stream when it's started with Start
+func WithAdviceHandler(h AdviceHandler) Option {
+	return func(o *Options) {
+		o.AdviceHandler = h
+	}
+}
+
+// WithSupervision has the Stream respond to panicking Processors according
+// to the provided Supervision
+func WithSupervision(s Supervision) Option {
+	return func(o *Options) {
+		o.Supervision = s
+	}
+}
+
+// WithCheckpointing has the Stream periodically checkpoint the state of its
+// Processors, and restore that state whenever it's started
+func WithCheckpointing(cp Checkpointing) Option {
+	return func(o *Options) {
+		o.Checkpointing = cp
+	}
+}
+
+// WithMetrics has the Stream pass the metrics recorded by its Processors to
+// the provided Sink
+func WithMetrics(s metrics.Sink) Option {
+	return func(o *Options) {
+		o.Metrics = s
+	}
+}
+
+// WithTracing has the Stream's Processors start a Span with the provided
+// Tracer for each message they handle
+func WithTracing(t trace.Tracer) Option {
+	return func(o *Options) {
+		o.Tracer = t
+	}
+}
+
+// WithBuffer has the channels between the Stream's Processors hold the
+// provided number of messages, so that a burst at one Processor doesn't stall
+// those before it. It can be overridden for part of the Stream using
+// node.Buffered
+func WithBuffer(size int) Option {
+	return func(o *Options) {
+		o.Buffer = size
+	}
+}
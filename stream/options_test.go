@@ -0,0 +1,38 @@
+package stream_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream"
+	"github.com/kode4food/caravan/stream/trace"
+)
+
+func TestOptions(t *testing.T) {
+	as := assert.New(t)
+
+	logger := slog.Default()
+	tracer := trace.Noop()
+	base := stream.Options{Name: "base"}
+	opts := base.Apply(
+		stream.WithName("orders"),
+		stream.WithLogger(logger),
+		stream.WithSupervision(stream.Supervision{
+			Policy:  stream.RestartNode,
+			Backoff: time.Millisecond,
+		}),
+		stream.WithTracing(tracer),
+	)
+
+	as.Equal("base", base.Name)
+	as.Equal("orders", opts.Name)
+	as.Same(logger, opts.Logger)
+	as.Equal(stream.RestartNode, opts.Supervision.Policy)
+	as.Equal(time.Millisecond, opts.Supervision.Backoff)
+	as.Equal(tracer, opts.Tracer)
+	as.Nil(opts.Metrics)
+	as.Nil(opts.AdviceHandler)
+}
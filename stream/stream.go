@@ -1,11 +1,17 @@
 package stream
 
 import (
+	gocontext "context"
 	"errors"
 	"log/slog"
+	"reflect"
+	"runtime/debug"
+	"sync"
 	"time"
 
 	"github.com/kode4food/caravan/stream/context"
+	"github.com/kode4food/caravan/stream/metrics"
+	"github.com/kode4food/caravan/stream/topology"
 )
 
 type (
@@ -19,14 +25,46 @@ type (
 		// programmer first crack at the Advice being received on the Stream's
 		// monitor channel
 		StartWith(AdviceHandler) Running
+
+		// With returns a copy of the Stream with the provided Options
+		// applied
+		With(...Option) Stream
+
+		// Topology describes the Stream's Processors and the connections
+		// between them
+		Topology() *topology.Topology
 	}
 
 	Running interface {
-		// Stop instructs the Stream to stop processing
+		// Stop instructs the Stream to stop processing. If the Stream has
+		// already stopped or completed on its own, ErrAlreadyStopped is
+		// returned
 		Stop() error
 
-		// IsRunning returns whether the Stream is processing messages
+		// Drain instructs the Stream's sources to stop pulling new messages,
+		// waits for in-flight messages to reach the sink, and then stops the
+		// Stream. If the Context is done first, the Stream is stopped and
+		// the Context's error is returned
+		Drain(gocontext.Context) error
+
+		// Done returns a channel that is closed once all the Stream's
+		// Processors have returned
+		Done() <-chan struct{}
+
+		// Wait blocks until all the Stream's Processors have returned, and
+		// then returns the error that terminated the Stream, if any. A
+		// Stream that completes on its own or is stopped returns nil. Fatal
+		// Advice is reported even if an AdviceHandler doesn't act on it
+		Wait() error
+
+		// IsRunning returns whether the Stream is processing messages. It
+		// becomes false once the Stream is stopped or completes on its own
 		IsRunning() bool
+
+		// Metrics returns a Snapshot of the messages received, forwarded
+		// and failed by each of the Stream's Processors, along with their
+		// latency and the time they spent blocked on the downstream
+		Metrics() *metrics.Snapshot
 	}
 
 	// AdviceHandler is provided to Stream.StartWith so that the programmer may
@@ -35,6 +73,60 @@ type (
 	// received Advice. Not calling next() will short-circuit that behavior
 	AdviceHandler func(a context.Advice, next func())
 
+	// Supervision determines how a Stream responds to a panicking Processor.
+	// The zero value stops the Stream
+	Supervision struct {
+		// Policy selects the response to a panic
+		Policy Policy
+
+		// MaxRestarts limits the number of restarts performed over the life
+		// of the Stream, after which a panic stops it. Zero means no limit
+		MaxRestarts int
+
+		// Backoff is the delay before restarting the Stream. It doubles with
+		// each subsequent restart
+		Backoff time.Duration
+
+		// MaxBackoff caps the delay between Stream restarts. Zero means no
+		// cap
+		MaxBackoff time.Duration
+	}
+
+	// Policy is the response a Supervision applies to a panicking Processor
+	Policy int
+
+	// Checkpointing determines how often a Stream captures a consistent
+	// Snapshot of its Processors' state, and where those Snapshots are kept
+	Checkpointing struct {
+		// Store keeps the Stream's most recent Snapshot
+		Store CheckpointStore
+
+		// Interval is the time between Snapshots
+		Interval time.Duration
+
+		// Codec encodes the state captured by each Snapshot, so that a
+		// Store can keep it outside the process. If nil, the Snapshot holds
+		// the values captured by the Processors
+		Codec context.Codec
+	}
+
+	// CheckpointStore keeps the most recent Snapshot of a Stream
+	CheckpointStore interface {
+		// Save stores the Snapshot, replacing any previous Snapshot
+		Save(*Snapshot) error
+
+		// Load returns the most recently saved Snapshot, if there is one
+		Load() (*Snapshot, bool, error)
+	}
+
+	// Snapshot is the state of a Stream's Processors, including the offsets
+	// of its Topic consumers, captured at a consistent point in the Stream.
+	// If the Stream's Checkpointing has a Codec, each state is a []byte
+	Snapshot struct {
+		ID    uint64
+		State map[string]any
+	}
+
 	// Processor is a function that processes part of a Stream topology.
 	// Recoverable and fatal errors can be sent to the context.Context's
 	// Monitor channel.
@@ -50,19 +142,85 @@ type (
 	Sink struct{}
 )
 
+// Supported Supervision Policies
+const (
+	// StopStream reports the panic as Fatal Advice, stopping the Stream
+	StopStream Policy = iota
+
+	// RestartNode restarts the panicking Processor in place
+	RestartNode
+
+	// RestartStream stops all the Stream's Processors and starts them again
+	// once the Supervision's Backoff has elapsed
+	RestartStream
+)
+
 var (
 	ErrReturnedLate   = errors.New("processor returned late")
 	ErrAlreadyStopped = errors.New("stream already stopped")
 )
 
-// Start begins the Processor in a new go routine, logging any abnormalities
+// composites holds the code pointers of the Processors marked by Composite
+var composites sync.Map
+
+// Composite marks the provided Processor as one that starts other Processors
+// and declares how they're connected, using the Context's Connect method.
+// When a Stream's topology is described, a composite Processor is called so
+// that it can describe the Processors it starts, while any other Processor is
+// recorded without being called. Every Processor created by the same function
+// literal is marked along with it
+func Composite[In, Out any](p Processor[In, Out]) Processor[In, Out] {
+	composites.Store(reflect.ValueOf(p).Pointer(), struct{}{})
+	return p
+}
+
+// Start begins the Processor in a new go routine, logging any abnormalities.
+// A panic in the Processor is recovered and reported to the Context, which
+// may have the Processor restarted. If the Context is describing a topology,
+// the Processor is recorded instead. A composite Processor is then called
+// directly, so that it can describe the Processors it starts
 func (p Processor[In, Out]) Start(c *context.Context[In, Out]) {
-	go func() {
-		start := time.Now().UnixNano() / int64(time.Millisecond)
-		p(c)
-		end := time.Now().UnixNano() / int64(time.Millisecond)
-		if end-start > 1 && !c.IsDone() {
-			slog.Debug(ErrReturnedLate.Error())
+	kind := topology.KindOf(p)
+	if c.Describing() {
+		c = c.Describe(kind)
+		if p.isComposite() {
+			p(c)
+		}
+		return
+	}
+	c = c.Measure(kind).Trace(kind, p.isSource())
+	c.Go(func() {
+		for p.run(c) {
+		}
+	})
+}
+
+// isSource returns whether the Processor generates messages from outside its
+// Stream, as indicated by its input being Source messages
+func (Processor[In, Out]) isSource() bool {
+	_, ok := any((*In)(nil)).(*Source)
+	return ok
+}
+
+func (p Processor[In, Out]) isComposite() bool {
+	_, ok := composites.Load(reflect.ValueOf(p).Pointer())
+	return ok
+}
+
+func (p Processor[In, Out]) run(c *context.Context[In, Out]) (restart bool) {
+	defer c.EndSpan()
+	defer func() {
+		if v := recover(); v != nil {
+			restart = c.Recover(v, debug.Stack())
 		}
 	}()
+	start := time.Now().UnixNano() / int64(time.Millisecond)
+	p(c)
+	end := time.Now().UnixNano() / int64(time.Millisecond)
+	if end-start > 1 && !c.IsDone() {
+		c.Logger().LogAttrs(gocontext.Background(), slog.LevelDebug,
+			ErrReturnedLate.Error(), c.Origin().LogAttrs()...,
+		)
+	}
+	return false
 }
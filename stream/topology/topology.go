@@ -0,0 +1,252 @@
+package topology
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type (
+	// Topology describes the Processors of a Stream and the connections
+	// between them
+	Topology struct {
+		Nodes []*Node `json:"nodes"`
+		Edges []*Edge `json:"edges"`
+	}
+
+	// Node describes a single Processor within a Stream. The ID is the
+	// Processor's position within the Stream, and the Kind is the name of
+	// the function that constructed it, such as Map or Filter
+	Node struct {
+		ID   string `json:"id"`
+		Name string `json:"name,omitempty"`
+		Kind string `json:"kind"`
+		In   string `json:"in"`
+		Out  string `json:"out"`
+	}
+
+	// Edge is a connection that carries messages from one Node to another
+	Edge struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+
+	// Shape describes how a composite Processor connects the Processors it
+	// starts, which are its children
+	Shape int
+
+	// Builder collects the Processors of a Stream as they're described,
+	// and builds the resulting Topology
+	Builder struct {
+		entries map[string]*entry
+		order   []string
+		mu      sync.Mutex
+	}
+
+	entry struct {
+		*Node
+		shape    Shape
+		children []string
+		root     bool
+	}
+)
+
+// Supported Shapes
+const (
+	// Leaf is a Processor that doesn't start other Processors
+	Leaf Shape = iota
+
+	// Sequence connects each child to the one started after it, such as
+	// Bind does
+	Sequence
+
+	// Parallel runs its children side by side, sharing its input and output,
+	// such as Merge does. It doesn't appear in the Topology itself
+	Parallel
+
+	// FanIn combines the output of its children, such as Zip does
+	FanIn
+
+	// FanOut sends its input to each of its children, such as Split does
+	FanOut
+)
+
+// MakeBuilder instantiates a new Builder
+func MakeBuilder() *Builder {
+	return &Builder{
+		entries: map[string]*entry{},
+	}
+}
+
+// Add records a Node as a child of the Node identified by parent. If a Node
+// with the same ID has already been recorded, such as when a Processor is
+// wrapped by another, the fields of the new Node take precedence
+func (b *Builder) Add(parent string, n *Node) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if e, ok := b.entries[n.ID]; ok {
+		e.Kind = n.Kind
+		e.In = n.In
+		e.Out = n.Out
+		if n.Name != "" {
+			e.Name = n.Name
+		}
+		return
+	}
+	p, ok := b.entries[parent]
+	if ok {
+		p.children = append(p.children, n.ID)
+	}
+	b.entries[n.ID] = &entry{
+		Node: n,
+		root: !ok,
+	}
+	b.order = append(b.order, n.ID)
+}
+
+// Connect sets the Shape of the Node with the provided ID
+func (b *Builder) Connect(id string, s Shape) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if e, ok := b.entries[id]; ok {
+		e.shape = s
+	}
+}
+
+// Build returns the Topology of the Nodes that have been recorded. Nodes
+// whose Shape is Sequence or Parallel only connect their children, so they
+// don't appear in the result
+func (b *Builder) Build() *Topology {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	res := &Topology{
+		Nodes: []*Node{},
+		Edges: []*Edge{},
+	}
+	for _, id := range b.order {
+		e := b.entries[id]
+		if e.shape != Sequence && e.shape != Parallel {
+			n := *e.Node
+			res.Nodes = append(res.Nodes, &n)
+		}
+	}
+	for _, id := range b.order {
+		if b.entries[id].root {
+			b.connect(res, id)
+		}
+	}
+	pos := make(map[string]int, len(b.order))
+	for i, id := range b.order {
+		pos[id] = i
+	}
+	slices.SortStableFunc(res.Edges, func(l, r *Edge) int {
+		if c := cmp.Compare(pos[l.From], pos[r.From]); c != 0 {
+			return c
+		}
+		return cmp.Compare(pos[l.To], pos[r.To])
+	})
+	return res
+}
+
+// connect adds the Edges within the Node with the provided ID to the
+// Topology, returning the Nodes that receive its input and those that
+// produce its output
+func (b *Builder) connect(t *Topology, id string) ([]string, []string) {
+	e := b.entries[id]
+	switch e.shape {
+	case Sequence:
+		var first, last []string
+		for i, child := range e.children {
+			in, out := b.connect(t, child)
+			if i == 0 {
+				first = in
+			} else {
+				t.link(last, in)
+			}
+			last = out
+		}
+		return first, last
+	case Parallel:
+		var ins, outs []string
+		for _, child := range e.children {
+			in, out := b.connect(t, child)
+			ins = append(ins, in...)
+			outs = append(outs, out...)
+		}
+		return ins, outs
+	case FanIn:
+		var ins []string
+		for _, child := range e.children {
+			in, out := b.connect(t, child)
+			t.link(out, []string{id})
+			ins = append(ins, in...)
+		}
+		return ins, []string{id}
+	case FanOut:
+		var outs []string
+		for _, child := range e.children {
+			in, out := b.connect(t, child)
+			t.link([]string{id}, in)
+			outs = append(outs, out...)
+		}
+		return []string{id}, outs
+	default:
+		return []string{id}, []string{id}
+	}
+}
+
+func (t *Topology) link(from, to []string) {
+	for _, f := range from {
+		for _, d := range to {
+			t.Edges = append(t.Edges, &Edge{From: f, To: d})
+		}
+	}
+}
+
+// DOT renders the Topology in the Graphviz DOT language
+func (t *Topology) DOT() string {
+	var buf strings.Builder
+	buf.WriteString("digraph stream {\n")
+	buf.WriteString("\trankdir=LR;\n")
+	for _, n := range t.Nodes {
+		_, _ = fmt.Fprintf(&buf, "\t%q [label=%q];\n", n.ID, n.label())
+	}
+	for _, e := range t.Edges {
+		_, _ = fmt.Fprintf(&buf, "\t%q -> %q;\n", e.From, e.To)
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+func (n *Node) label() string {
+	types := n.In + " -> " + n.Out
+	if n.Name != "" {
+		return n.Name + "\n" + n.Kind + "\n" + types
+	}
+	return n.Kind + "\n" + types
+}
+
+// KindOf returns the name of the function that constructed the provided
+// function, such as Map for the Processors returned by node.Map. If the
+// function isn't a closure, its own name is returned
+func KindOf(fn any) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "Processor"
+	}
+	name := f.Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	if _, rest, ok := strings.Cut(name, "."); ok {
+		name = rest
+	}
+	if i := strings.IndexAny(name, "[."); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
@@ -0,0 +1,59 @@
+package topology_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream/node"
+	"github.com/kode4food/caravan/stream/topology"
+)
+
+func TestBuilder(t *testing.T) {
+	as := assert.New(t)
+
+	b := topology.MakeBuilder()
+	b.Add("", &topology.Node{ID: "/", Kind: "Bind"})
+	b.Connect("/", topology.Sequence)
+	b.Add("/", &topology.Node{ID: "/0", Kind: "Merge"})
+	b.Connect("/0", topology.Parallel)
+	b.Add("/0", &topology.Node{ID: "/0/0", Kind: "Generate"})
+	b.Add("/0", &topology.Node{ID: "/0/1", Kind: "Generate"})
+	b.Add("/", &topology.Node{ID: "/1", Kind: "Forward"})
+	b.Add("/", &topology.Node{ID: "/1", Name: "last", Kind: "Map"})
+
+	top := b.Build()
+	as.Equal([]*topology.Node{
+		{ID: "/0/0", Kind: "Generate"},
+		{ID: "/0/1", Kind: "Generate"},
+		{ID: "/1", Name: "last", Kind: "Map"},
+	}, top.Nodes)
+	as.Equal([]*topology.Edge{
+		{From: "/0/0", To: "/1"},
+		{From: "/0/1", To: "/1"},
+	}, top.Edges)
+}
+
+func TestBuilderFanOut(t *testing.T) {
+	as := assert.New(t)
+
+	b := topology.MakeBuilder()
+	b.Add("", &topology.Node{ID: "/", Kind: "Split"})
+	b.Connect("/", topology.FanOut)
+	b.Add("/", &topology.Node{ID: "/1/0", Kind: "ForEach"})
+	b.Add("/", &topology.Node{ID: "/1/1", Kind: "ForEach"})
+
+	as.Equal([]*topology.Edge{
+		{From: "/", To: "/1/0"},
+		{From: "/", To: "/1/1"},
+	}, b.Build().Edges)
+}
+
+func TestKindOf(t *testing.T) {
+	as := assert.New(t)
+
+	as.Equal("Map", topology.KindOf(node.Map(func(i int) int { return i })))
+	as.Equal("Forward", topology.KindOf(node.Forward[int]))
+	as.Equal("TestKindOf", topology.KindOf(func() {}))
+	as.Equal("MakeBuilder", topology.KindOf(topology.MakeBuilder))
+}
@@ -0,0 +1,93 @@
+package trace
+
+import (
+	"encoding/binary"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+type (
+	// Recorder is a Tracer that keeps the Spans it starts in memory, which
+	// is mostly useful for testing
+	Recorder struct {
+		spans []*RecordedSpan
+		mu    sync.Mutex
+	}
+
+	// RecordedSpan is a Span that has been ended by a Recorder
+	RecordedSpan struct {
+		Node
+		Context SpanContext
+		Parent  SpanID
+		Start   time.Time
+		End     time.Time
+		Err     error
+	}
+
+	recording struct {
+		recorder *Recorder
+		span     RecordedSpan
+		once     sync.Once
+	}
+)
+
+// MakeRecorder instantiates a new Recorder
+func MakeRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Start begins a Span for the provided Node, as a child of the parent
+// SpanContext if it's valid
+func (r *Recorder) Start(parent SpanContext, node Node) Span {
+	ctx := SpanContext{TraceID: parent.TraceID}
+	if !parent.IsValid() {
+		binary.BigEndian.PutUint64(ctx.TraceID[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(ctx.TraceID[8:], rand.Uint64())
+	}
+	binary.BigEndian.PutUint64(ctx.SpanID[:], rand.Uint64())
+	return &recording{
+		recorder: r,
+		span: RecordedSpan{
+			Node:    node,
+			Context: ctx,
+			Parent:  parent.SpanID,
+			Start:   time.Now(),
+		},
+	}
+}
+
+// Spans returns the Spans that have ended, in the order they ended
+func (r *Recorder) Spans() []*RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.spans)
+}
+
+// Trace returns the Spans that have ended as part of the provided trace, in
+// the order they ended
+func (r *Recorder) Trace(id TraceID) []*RecordedSpan {
+	var res []*RecordedSpan
+	for _, s := range r.Spans() {
+		if s.Context.TraceID == id {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func (s *recording) Context() SpanContext {
+	return s.span.Context
+}
+
+func (s *recording) End(err error) {
+	s.once.Do(func() {
+		span := s.span
+		span.End = time.Now()
+		span.Err = err
+		s.recorder.mu.Lock()
+		defer s.recorder.mu.Unlock()
+		s.recorder.spans = append(s.recorder.spans, &span)
+	})
+}
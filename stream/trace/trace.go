@@ -0,0 +1,131 @@
+package trace
+
+import "encoding/hex"
+
+type (
+	// Tracer starts a Span each time a Processor handles a message. It's
+	// called from the Processors' own go routines, so it must be safe for
+	// concurrent use. An adapter for a tracing library, such as
+	// OpenTelemetry, implements this interface
+	Tracer interface {
+		// Start begins a Span for the provided Node, as a child of the
+		// parent SpanContext. If the parent isn't valid, the Span begins a
+		// new trace
+		Start(parent SpanContext, node Node) Span
+	}
+
+	// Span is the handling of a single message by a single Processor
+	Span interface {
+		// Context returns the SpanContext that identifies the Span, which is
+		// propagated to the results forwarded while handling the message
+		Context() SpanContext
+
+		// End completes the Span. The error is the last one reported by the
+		// Processor while handling the message, if any
+		End(err error)
+	}
+
+	// Node identifies the Processor that a Span belongs to. The ID is the
+	// Processor's position within the Stream's topology
+	Node struct {
+		ID   string
+		Name string
+		Kind string
+	}
+
+	// SpanContext identifies a Span, and the trace it belongs to, as it's
+	// propagated from one Processor to the next
+	SpanContext struct {
+		TraceID TraceID
+		SpanID  SpanID
+	}
+
+	// TraceID identifies a trace
+	TraceID [16]byte
+
+	// SpanID identifies a Span within a trace
+	SpanID [8]byte
+
+	// Carrier is implemented by messages that carry a SpanContext, whether
+	// in a header of their own or by being wrapped in a Traced message. A
+	// Processor's Span is a child of the SpanContext carried by the message
+	// it handles, and the results it forwards carry the Span's SpanContext
+	Carrier interface {
+		// SpanContext returns the SpanContext carried by the message
+		SpanContext() SpanContext
+
+		// WithSpanContext returns a copy of the message that carries the
+		// provided SpanContext. The copy must be of the same type
+		WithSpanContext(SpanContext) Carrier
+	}
+
+	// Traced wraps a message so that it carries a SpanContext
+	Traced[Msg any] struct {
+		Msg  Msg
+		Span SpanContext
+	}
+
+	noop struct{}
+
+	noopSpan struct {
+		ctx SpanContext
+	}
+)
+
+// Noop returns a Tracer that doesn't record anything. Its Spans propagate
+// the SpanContext of their parent unchanged
+func Noop() Tracer {
+	return noop{}
+}
+
+func (noop) Start(parent SpanContext, _ Node) Span {
+	return noopSpan{ctx: parent}
+}
+
+func (s noopSpan) Context() SpanContext {
+	return s.ctx
+}
+
+func (noopSpan) End(error) {}
+
+// Trace wraps a message so that it carries the provided SpanContext
+func Trace[Msg any](msg Msg, sc SpanContext) Traced[Msg] {
+	return Traced[Msg]{
+		Msg:  msg,
+		Span: sc,
+	}
+}
+
+// SpanContext returns the SpanContext carried by the message
+func (t Traced[Msg]) SpanContext() SpanContext {
+	return t.Span
+}
+
+// WithSpanContext returns a copy of the message that carries the provided
+// SpanContext
+func (t Traced[Msg]) WithSpanContext(sc SpanContext) Carrier {
+	t.Span = sc
+	return t
+}
+
+// SpanContextOf returns the SpanContext carried by a message, if it's a
+// Carrier
+func SpanContextOf(msg any) SpanContext {
+	if c, ok := msg.(Carrier); ok {
+		return c.SpanContext()
+	}
+	return SpanContext{}
+}
+
+// IsValid returns whether the SpanContext identifies a Span
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
@@ -0,0 +1,74 @@
+package trace_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kode4food/caravan/stream/trace"
+)
+
+func TestNoop(t *testing.T) {
+	as := assert.New(t)
+
+	parent := trace.SpanContext{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	}
+	s := trace.Noop().Start(parent, trace.Node{ID: "/"})
+	as.Equal(parent, s.Context())
+	s.End(nil)
+}
+
+func TestTraced(t *testing.T) {
+	as := assert.New(t)
+
+	sc := trace.SpanContext{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	}
+	as.True(sc.IsValid())
+	as.False(trace.SpanContext{}.IsValid())
+	as.Equal("0200000000000000", sc.SpanID.String())
+
+	msg := trace.Trace("hello", trace.SpanContext{})
+	as.False(trace.SpanContextOf(msg).IsValid())
+	as.False(trace.SpanContextOf("hello").IsValid())
+
+	res, ok := msg.WithSpanContext(sc).(trace.Traced[string])
+	as.True(ok)
+	as.Equal("hello", res.Msg)
+	as.Equal(sc, trace.SpanContextOf(res))
+}
+
+func TestRecorder(t *testing.T) {
+	as := assert.New(t)
+
+	r := trace.MakeRecorder()
+	root := r.Start(trace.SpanContext{}, trace.Node{ID: "/0", Kind: "Map"})
+	as.True(root.Context().IsValid())
+
+	child := r.Start(root.Context(), trace.Node{ID: "/1"})
+	as.Equal(root.Context().TraceID, child.Context().TraceID)
+	as.NotEqual(root.Context().SpanID, child.Context().SpanID)
+
+	err := errors.New("boom")
+	child.End(err)
+	child.End(nil)
+	root.End(nil)
+
+	spans := r.Spans()
+	as.Len(spans, 2)
+	as.Equal("/1", spans[0].ID)
+	as.Equal(root.Context().SpanID, spans[0].Parent)
+	as.Equal(err, spans[0].Err)
+	as.Equal("Map", spans[1].Kind)
+	as.Equal(trace.SpanID{}, spans[1].Parent)
+	as.False(spans[1].End.Before(spans[1].Start))
+
+	other := r.Start(trace.SpanContext{}, trace.Node{ID: "/0"})
+	other.End(nil)
+	as.Len(r.Trace(root.Context().TraceID), 2)
+	as.Len(r.Trace(other.Context().TraceID), 1)
+}
@@ -0,0 +1,43 @@
+package table
+
+type (
+	// Change is an entry in a Table's change log. Old is the row before the
+	// change, and is nil when a row is inserted. New is the row after the
+	// change, and is nil when a row is deleted. Both include every Column
+	// of the Table
+	Change[Key comparable, Value any] struct {
+		Kind ChangeKind
+		Key  Key
+		Old  []Value
+		New  []Value
+	}
+
+	// ChangeKind identifies how a Change affected its row
+	ChangeKind int
+)
+
+// Supported ChangeKinds
+const (
+	// Inserted is a row that was added to the Table
+	Inserted ChangeKind = iota
+
+	// Updated is a row whose Columns were set
+	Updated
+
+	// Deleted is a row that was removed from the Table
+	Deleted
+)
+
+// String returns the name of the ChangeKind
+func (k ChangeKind) String() string {
+	switch k {
+	case Inserted:
+		return "inserted"
+	case Updated:
+		return "updated"
+	case Deleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
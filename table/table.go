@@ -1,6 +1,11 @@
 package table
 
-import "errors"
+import (
+	"errors"
+	"iter"
+
+	"github.com/kode4food/caravan/topic"
+)
 
 type (
 	// Table is an interface that associates a Key with multiple named Columns.
@@ -28,6 +33,15 @@ type (
 		// Range iterates over all rows in the table, calling fn for each row.
 		// If fn returns false, iteration stops.
 		Range(fn func(Key, []Value) bool)
+
+		// All returns an iterator over all rows in the table
+		All() iter.Seq2[Key, []Value]
+
+		// Changes returns the Topic that the Table's change log is produced
+		// to. Every row inserted, updated or deleted by its Setters and
+		// Delete is produced as a Change, in the order the changes were made.
+		// Changes made before the first call aren't produced
+		Changes() topic.Topic[Change[Key, Value]]
 	}
 
 	// ColumnName is exactly what you think it is
@@ -1,6 +1,10 @@
 package topic
 
-import "github.com/kode4food/caravan/message"
+import (
+	"iter"
+
+	"github.com/kode4food/caravan/message"
+)
 
 type (
 	// Topic is where you put your stuff. They are implemented as a
@@ -14,6 +18,16 @@ type (
 
 		// NewConsumer returns a new Consumer for this Topic
 		NewConsumer() Consumer[Msg]
+
+		// NewConsumerFrom returns a new Consumer for this Topic that receives
+		// its messages paired with their offsets, beginning at the provided
+		// offset. If that offset is no longer retained, the Consumer begins
+		// with the earliest retained message
+		NewConsumerFrom(offset uint64) Consumer[Entry[Msg]]
+
+		// Retained returns an iterator over the messages currently retained
+		// by the Topic, paired with their offsets
+		Retained() iter.Seq2[uint64, Msg]
 	}
 
 	// Producer exposes a way to push messages to its associated Topic.
@@ -25,4 +39,17 @@ type (
 	// Each Consumer created independently tracks its own position within
 	// the Topic
 	Consumer[Msg any] message.ClosingReceiver[Msg]
+
+	// Entry is a message received from a Topic, paired with its offset
+	Entry[Msg any] struct {
+		Offset  uint64
+		Message Msg
+	}
 )
+
+// Replay returns a bounded snapshot of the Topic's currently retained Log as
+// an iterator of offsets and messages. Messages produced after iteration
+// begins are not included
+func Replay[Msg any](t Topic[Msg]) iter.Seq2[uint64, Msg] {
+	return t.Retained()
+}